@@ -1,75 +1,264 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"gateway/config"
 	api "gateway/internal/api"
+	"gateway/internal/api/middleware"
 	"gateway/internal/api/token"
+	"gateway/internal/audit"
+	pbc "gateway/internal/generated/company"
+	pbd "gateway/internal/generated/debts"
+	pbp "gateway/internal/generated/products"
+	pbu "gateway/internal/generated/user"
+	"gateway/internal/health"
+	"gateway/internal/metrics"
 	"gateway/internal/minio"
+	"gateway/internal/rbac"
+	"gateway/internal/tracing"
+	"gateway/pkg"
 	logger "gateway/pkg/logs"
-	"github.com/casbin/casbin/v2"
-	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
+	// bootLog serves until the configured logger is up and after it is closed.
+	bootLog := slog.New(slog.NewTextHandler(os.Stderr, nil))
 
-	cfg := config.Load()
+	cfg, err := config.Load(bootLog)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	path, err := os.Getwd()
 	if err != nil {
-		log.Println("mana 3")
 		log.Fatal(err)
 	}
 
-	casbinEnforcer, err := casbin.NewEnforcer(path+"/internal/casbin/model.conf", path+"/internal/casbin/policy.csv")
+	policy, err := rbac.NewPolicy(path+"/internal/casbin/model.conf", path+"/internal/casbin/policy.csv",
+		cfg.CASBIN_COMPANY_POLICY, cfg.CASBIN_POLICY_VERSIONS, cfg.CASBIN_POLICY_VERSIONS_KEEP)
 	if err != nil {
-		log.Println("mana 2")
 		log.Fatal(err)
 	}
 
-	err = minio.InitMiniOClient()
+	ownership, err := rbac.NewOwnership(path+"/internal/casbin/ownership_model.conf", path+"/internal/casbin/ownership_policy.csv",
+		time.Minute*time.Duration(cfg.SALE_EDIT_WINDOW), cfg.BACKEND_TIMEZONE)
 	if err != nil {
-		log.Println("mana 1 ")
 		log.Fatal(err)
 	}
 
-	err = token.ConfigToken(cfg)
+	tokens, err := newTokens(cfg)
 	if err != nil {
-		log.Println("mana 0 ")
 		log.Fatal(err)
 	}
 
-	log1 := logger.NewLogger()
+	log1, kafka, err := logger.NewLogger(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	r := api.NewRouter(casbinEnforcer, cfg, log1)
+	redactor, err := logger.NewRedactorFromConfig(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	ips, err := get()
+	auditWriter, err := logger.NewKafkaWriter(cfg.KAFKA_BROKERS, cfg.AUDIT_KAFKA_TOPIC, cfg.KAFKA_LOG_BUFFER, cfg.AUDIT_FALLBACK_FILE, log1)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	log1.Debug(fmt.Sprintf("[%s] Api Gateway is running at IP: %s",
-		time.Now().Format("02-01-2006 15:04:05"), ips))
+	trail, err := audit.New(auditWriter, redactor.JSON, cfg.AUDIT_FILE, cfg.AUDIT_RECENT)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	log.Fatal(r.Run(cfg.API_GATEWAY))
-}
+	tracer, err := tracing.New(context.Background(), cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Lines logged with the context of a request carry its request ID.
+	log1 = slog.New(logger.NewContextHandler(log1.Handler(), middleware.LogAttrs))
 
-// Getting
-func get() (string, error) {
-	resp, err := http.Get("https://api.ipify.org?format=text")
+	conns, err := pkg.Dial(cfg, log1, metrics.UnaryClientInterceptor(), tracing.UnaryClientInterceptor(), middleware.MetadataInterceptor())
 	if err != nil {
-		return "", err
+		log.Fatal(err)
+	}
+
+	media, err := minio.NewClient(cfg)
+	if err != nil {
+		log.Fatal(err)
 	}
-	defer resp.Body.Close()
 
-	ip, err := io.ReadAll(resp.Body)
+	checker := health.NewChecker(time.Second*time.Duration(cfg.HEALTH_PROBE_TIMEOUT), cfg.READINESS_CRITICAL,
+		// The company API is served by the user service, so it is told
+		// apart by its service name.
+		health.Probe{Name: "user", Check: health.GRPC(conns.User, pbu.AuthService_ServiceDesc.ServiceName)},
+		health.Probe{Name: "product", Check: health.GRPC(conns.Product, pbp.Products_ServiceDesc.ServiceName)},
+		health.Probe{Name: "company", Check: health.GRPC(conns.User, pbc.CompanyService_ServiceDesc.ServiceName)},
+		health.Probe{Name: "debt", Check: health.GRPC(conns.Debt, pbd.DebtsService_ServiceDesc.ServiceName)},
+		health.Probe{Name: "minio", Check: media.Ping},
+		health.Probe{Name: "kafka", Check: kafka.Ping},
+	)
+
+	// Background workers run until stopWorkers is called on shutdown.
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+
+	if keys := tokens.Keys(); keys != nil {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			keys.Run(workersCtx, log1)
+		}()
+	}
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		conns.Watch(workersCtx)
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		policy.Watch(workersCtx, log1, time.Second*time.Duration(cfg.CASBIN_WATCH_INTERVAL))
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		ownership.Watch(workersCtx, log1, time.Second*time.Duration(cfg.CASBIN_WATCH_INTERVAL))
+	}()
+
+	r, err := api.NewRouter(conns, media, checker, policy, ownership, trail, tokens, cfg, log1)
 	if err != nil {
-		return "", err
+		log.Fatal(err)
+	}
+
+	if cfg.POLICY_CHECK != "off" {
+		report, err := policy.Check()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !report.OK() {
+			log1.Error("Routes and policy have drifted", "report", report.String())
+			if cfg.POLICY_CHECK == "strict" {
+				log.Fatalf("routes and policy have drifted:\n%s", report)
+			}
+		}
+	}
+
+	srv := &http.Server{
+		Addr:    cfg.API_GATEWAY,
+		Handler: r,
+	}
+
+	// Metrics are served on their own admin listener, away from the
+	// unauthenticated public routes.
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.Handler())
+	metricsSrv := &http.Server{
+		Addr:    cfg.METRICS_ADDR,
+		Handler: metricsMux,
+	}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		log1.Info("Api Gateway is listening", "addr", cfg.API_GATEWAY)
+		serveErr <- srv.ListenAndServe()
+	}()
+	go func() {
+		log1.Info("Metrics are served", "addr", cfg.METRICS_ADDR)
+		serveErr <- metricsSrv.ListenAndServe()
+	}()
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	exitCode := 0
+	select {
+	case <-signals.Done():
+		log1.Info("Shutdown signal received, draining in-flight requests", "timeout_seconds", cfg.SHUTDOWN_TIMEOUT)
+	case err := <-serveErr:
+		log1.Error("Api Gateway stopped serving", "error", err.Error())
+		exitCode = 1
+	}
+	// A second signal kills the process right away.
+	stopSignals()
+
+	// Stop accepting requests and let those in flight, e.g. sales that are
+	// halfway through their backend calls, finish.
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), time.Second*time.Duration(cfg.SHUTDOWN_TIMEOUT))
+	if err := srv.Shutdown(drainCtx); err != nil {
+		log1.Error("Drain period expired, closing remaining connections", "error", err.Error())
+		srv.Close()
+		exitCode = 1
+	} else {
+		log1.Info("HTTP server stopped")
+	}
+	cancelDrain()
+	metricsSrv.Close()
+
+	stopWorkers()
+	workers.Wait()
+	log1.Info("Background workers stopped")
+
+	if err := trail.Close(); err != nil {
+		log1.Error("Error closing audit trail", "error", err.Error())
+		exitCode = 1
+	}
+
+	if err := conns.Close(log1); err != nil {
+		exitCode = 1
+	}
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := tracer.Close(flushCtx); err != nil {
+		log1.Error("Error flushing traces", "error", err.Error())
+		exitCode = 1
+	}
+	cancelFlush()
+
+	// The Kafka producer goes last so the steps above are still shipped.
+	log1.Info("Closing Kafka producer, shutdown complete")
+	if err := kafka.Close(); err != nil {
+		bootLog.Error("Error closing Kafka producer", "error", err.Error())
+		exitCode = 1
+	}
+
+	os.Exit(exitCode)
+}
+
+// newTokens builds the token manager from the configuration. A key set is
+// only loaded when access tokens are signed with something other than the
+// shared HS256 secret.
+func newTokens(cfg *config.Config) (*token.Manager, error) {
+	settings := token.Settings{
+		AccessSecret:  cfg.ACCESS_TOKEN,
+		RefreshSecret: cfg.REFRESH_TOKEN,
+		AccessTTL:     time.Hour * time.Duration(cfg.EXPIRED_ACCESS),
+		RefreshTTL:    time.Hour * time.Duration(cfg.EXPIRED_REFRESH),
+		ReuseGrace:    time.Second * time.Duration(cfg.REFRESH_REUSE_GRACE),
+	}
+
+	var keys *token.KeySet
+	if cfg.JWT_ALGORITHM != "" && cfg.JWT_ALGORITHM != "HS256" {
+		var err error
+		keys, err = token.NewKeySet(cfg.JWT_KEYS_DIR, cfg.JWT_ALGORITHM, time.Hour*time.Duration(cfg.JWT_KEY_ROTATION), settings.AccessTTL)
+		if err != nil {
+			return nil, err
+		}
+		settings.AcceptHS256 = cfg.JWT_ACCEPT_HS256
 	}
 
-	return string(ip), nil
+	return token.NewManager(settings, keys, token.NewMemoryStore(settings.RefreshTTL)), nil
 }
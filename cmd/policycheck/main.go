@@ -0,0 +1,84 @@
+// Command policycheck builds the gateway router and reports drift between
+// its routes and the Casbin policy. It exits with status 1 on drift.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gateway/config"
+	api "gateway/internal/api"
+	"gateway/internal/rbac"
+	"gateway/pkg"
+	"github.com/gin-gonic/gin"
+	"log"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+)
+
+func main() {
+	verbose := flag.Bool("v", false, "print the roles allowed on every route")
+	flag.Parse()
+
+	// The Kafka logger, MinIO, the health checker, the audit trail and the
+	// token manager of the gateway are not needed to inspect routes.
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	cfg, err := config.Load(logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	path, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	policy, err := rbac.NewPolicy(path+"/internal/casbin/model.conf", path+"/internal/casbin/policy.csv",
+		cfg.CASBIN_COMPANY_POLICY, cfg.CASBIN_POLICY_VERSIONS, cfg.CASBIN_POLICY_VERSIONS_KEEP)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ownership, err := rbac.NewOwnership(path+"/internal/casbin/ownership_model.conf", path+"/internal/casbin/ownership_policy.csv",
+		time.Minute*time.Duration(cfg.SALE_EDIT_WINDOW), cfg.BACKEND_TIMEZONE)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	conns, err := pkg.Dial(cfg, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	if _, err := api.NewRouter(conns, nil, nil, policy, ownership, nil, nil, cfg, logger); err != nil {
+		log.Fatal(err)
+	}
+
+	report, err := policy.Check()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *verbose {
+		routes := make([]string, 0, len(report.Access))
+		for route := range report.Access {
+			routes = append(routes, route)
+		}
+		sort.Strings(routes)
+
+		for _, route := range routes {
+			fmt.Printf("%-55s %v\n", route, report.Access[route])
+		}
+		fmt.Println()
+	}
+
+	if !report.OK() {
+		fmt.Print(report)
+		os.Exit(1)
+	}
+
+	fmt.Println("policy and routes are consistent")
+}
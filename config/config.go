@@ -1,45 +1,557 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	// The runtime image has no zoneinfo, BACKEND_TIMEZONE needs the
+	// embedded copy.
+	_ "time/tzdata"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cast"
 )
 
+// Dependencies are the names of the services the gateway depends on, as
+// used by READINESS_CRITICAL and reported by /readyz.
+var Dependencies = []string{"user", "product", "company", "debt", "minio", "kafka"}
+
+// Backend tunes the gRPC client of one backend service.
+type Backend struct {
+	// Addresses are the endpoints of the service. "dns:///host:port"
+	// stands for every address host resolves to. EndpointsFile, when set,
+	// replaces them with its lines. Both are refreshed every
+	// ResolveInterval.
+	Addresses       []string
+	EndpointsFile   string
+	ResolveInterval time.Duration
+	// Balancer is "round_robin" or "least_request".
+	Balancer string
+	// OutlierFailures consecutive unavailable or timed out calls eject an
+	// endpoint for OutlierEjection, unless MaxEjectedPercent of the
+	// endpoints are already ejected. Zero disables ejection.
+	OutlierFailures   int
+	OutlierEjection   time.Duration
+	MaxEjectedPercent int
+
+	// Timeout is the deadline of an RPC, retries included, when the caller
+	// has not set an earlier one.
+	Timeout time.Duration
+	// Retries is how many more times a failed idempotent read is tried.
+	Retries         int
+	RetryBackoff    time.Duration
+	RetryBackoffMax time.Duration
+	// RetryMethods are prefixes of the method names that are idempotent
+	// reads, e.g. "Get" for GetProduct.
+	RetryMethods []string
+	// BreakerFailures consecutive failures open the circuit breaker for
+	// BreakerCooldown. Zero disables the breaker.
+	BreakerFailures int
+	BreakerCooldown time.Duration
+
+	// TLS is "insecure" (plaintext, for local development), "tls" or
+	// "mtls", which also presents TLSCert and TLSKey to the backend.
+	TLS string
+	// TLSCA is a PEM bundle of the CAs to trust; the system roots are used
+	// when it is empty. The files are reread when they change.
+	TLSCA   string
+	TLSCert string
+	TLSKey  string
+	// TLSServerName overrides the host name checked in the server
+	// certificate, which defaults to the host of the address.
+	TLSServerName string
+
+	// CanaryAddresses, when set, are the endpoints of a new version of the
+	// service. Calls of the companies in CanaryCompanies all go there, other
+	// calls by CanaryWeights: the percentage per method name, with "*" for
+	// methods not listed.
+	CanaryAddresses []string
+	CanaryWeights   map[string]int
+	CanaryCompanies []string
+	// ShadowAddresses, when set, receive a copy of ShadowPercent percent of
+	// the successful reads (RetryMethods). Differences from the primary
+	// response are logged; the caller only ever sees the primary response.
+	ShadowAddresses []string
+	ShadowPercent   int
+}
+
 type Config struct {
-	USER_SERVICE    string
-	API_GATEWAY     string
-	PRODUCT_SERVICE string
-	DEBT_SERVICE    string
+	API_GATEWAY      string
+	SHUTDOWN_TIMEOUT int // seconds to drain in-flight requests
+	// METRICS_ADDR is the admin listener serving /metrics. It is kept off
+	// the public port, so it must not be published.
+	METRICS_ADDR string
+
+	// Backends are dialled at <HOST><PORT>, e.g. "product" + ":9091".
+	USER_SERVICE         string
+	USER_SERVICE_HOST    string
+	PRODUCT_SERVICE      string
+	PRODUCT_SERVICE_HOST string
+	DEBT_SERVICE         string
+	DEBT_SERVICE_HOST    string
+
+	// Client settings of each backend, read from <SERVICE>_TIMEOUT etc.
+	// with GRPC_TIMEOUT etc. as the defaults. <SERVICE>_ADDRESSES defaults
+	// to <HOST><PORT> above.
+	USER_SERVICE_CLIENT    Backend
+	PRODUCT_SERVICE_CLIENT Backend
+	DEBT_SERVICE_CLIENT    Backend
+
+	REFRESH_TOKEN       string
+	ACCESS_TOKEN        string
+	EXPIRED_ACCESS      int // hours
+	EXPIRED_REFRESH     int // hours
+	REFRESH_REUSE_GRACE int // seconds a rotated refresh token may be reused, 0 allows no reuse
+
+	JWT_ALGORITHM    string
+	JWT_KEYS_DIR     string
+	JWT_KEY_ROTATION int // hours, 0 disables rotation
+	JWT_ACCEPT_HS256 bool
+
+	BRANCH_ASSIGNMENTS_FILE string
+	BRANCH_CACHE_TTL        int // seconds
+	BRANCH_ALLOW_UNASSIGNED bool
+
+	CASBIN_COMPANY_POLICY       string
+	CASBIN_POLICY_VERSIONS      string
+	CASBIN_POLICY_VERSIONS_KEEP int
+	CASBIN_WATCH_INTERVAL       int    // seconds
+	POLICY_CHECK                string // strict, warn or off
+
+	SALE_EDIT_WINDOW int            // minutes
+	BACKEND_TIMEZONE *time.Location // zone of backend timestamps written without an offset
+
+	MINIO_ENDPOINT   string
+	MINIO_ACCESS_KEY string
+	MINIO_SECRET_KEY string
+	MINIO_USE_SSL    bool
+	MINIO_BUCKET     string
 
-	REFRESH_TOKEN  string
-	ACCESS_TOKEN   string
-	EXPIRED_ACCESS string
+	KAFKA_BROKERS           []string
+	KAFKA_LOG_TOPIC         string
+	KAFKA_LOG_LEVEL         string // minimum level forwarded to Kafka
+	KAFKA_LOG_BUFFER        int    // records waiting to be sent; more go to the fallback file
+	KAFKA_LOG_FALLBACK_FILE string // records Kafka did not take
+	LOG_DIR                 string // directory of LOG_FILE and KAFKA_LOG_FALLBACK_FILE when they are relative
+	LOG_FILE                string
+	LOG_LEVEL               string // minimum level written to LOG_FILE
+	LOG_MAX_SIZE            int    // MB; LOG_FILE is rotated when it would grow past it
+	LOG_MAX_AGE             int    // hours a LOG_FILE segment is written to, 0 for no limit
+	LOG_MAX_BACKUPS         int    // rotated segments kept
+	LOG_COMPRESS            bool   // gzip rotated segments
+	// LOG_REDACT_KEYS are attribute names whose values are never logged;
+	// a key matches if it contains one of them. LOG_REDACT_PATTERNS are the
+	// built-in patterns (phone, email, token, secret) masked in messages
+	// and values.
+	LOG_REDACT_KEYS     []string
+	LOG_REDACT_PATTERNS []string
+
+	SWAGGER_USER     string
+	SWAGGER_PASSWORD string
+
+	HEALTH_PROBE_TIMEOUT int      // seconds
+	READINESS_CRITICAL   []string // dependencies that make /readyz fail
+
+	// ACCESS_LOG_SAMPLE is the share of successful GET requests logged per
+	// route template, e.g. "/products=10,/healthz=0"; "*" covers the
+	// routes not listed. Other requests and failures are always logged.
+	ACCESS_LOG_SAMPLE map[string]int
+
+	AUDIT_KAFKA_TOPIC   string
+	AUDIT_FALLBACK_FILE string // events Kafka did not take
+	AUDIT_FILE          string // journal of the kept events, read back on start
+	AUDIT_RECENT        int    // events per company kept for GET /audit
+	AUDIT_MAX_PAYLOAD   int    // bytes; larger request bodies are not recorded
+
+	TRACING_EXPORTER       string // none, otlp, stdout or file
+	TRACING_SERVICE_NAME   string
+	TRACING_OTLP_ENDPOINT  string // host:port of an OTLP/gRPC collector
+	TRACING_OTLP_INSECURE  bool
+	TRACING_FILE           string
+	TRACING_SAMPLE_PERCENT int // share of new traces kept; callers' decisions are followed
 }
 
-func Load() *Config {
-	if err := godotenv.Load(".env"); err != nil {
-		log.Print("No .env file found?")
+// logLevels are the accepted values of LOG_LEVEL and KAFKA_LOG_LEVEL.
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// Load reads the configuration from the environment and from the file named
+// by CONFIG_FILE (".env" by default); the environment wins. Any variable KEY
+// may instead be given as KEY_FILE, the path of a file holding the value,
+// which suits Docker and Kubernetes secrets. All problems are reported at
+// once; a missing file is only logged to log.
+func Load(log *slog.Logger) (*Config, error) {
+	file := os.Getenv("CONFIG_FILE")
+	if file == "" {
+		file = ".env"
+	}
+	values, err := godotenv.Read(file)
+	if err != nil {
+		log.Info("Config file not read, using the environment only", "file", file, "error", err.Error())
 	}
 
+	l := &loader{file: values}
+
 	config := Config{}
-	config.USER_SERVICE = cast.ToString(Coalesce("USER_SERVICE", ":6006"))
-	config.API_GATEWAY = cast.ToString(Coalesce("API_GATEWAY", ":1111"))
-	config.PRODUCT_SERVICE = cast.ToString(Coalesce("PRODUCT_SERVICE", ":9091"))
-	config.DEBT_SERVICE = cast.ToString(Coalesce("DEBT_SERVICE", ":8075"))
+	config.API_GATEWAY = l.string("API_GATEWAY", ":1111")
+	config.SHUTDOWN_TIMEOUT = l.positive("SHUTDOWN_TIMEOUT", 30)
+	config.METRICS_ADDR = l.string("METRICS_ADDR", ":2112")
+
+	config.USER_SERVICE = l.string("USER_SERVICE", ":6006")
+	config.USER_SERVICE_HOST = l.string("USER_SERVICE_HOST", "crm-admin_auth")
+	config.PRODUCT_SERVICE = l.string("PRODUCT_SERVICE", ":9091")
+	config.PRODUCT_SERVICE_HOST = l.string("PRODUCT_SERVICE_HOST", "product")
+	config.DEBT_SERVICE = l.string("DEBT_SERVICE", ":8075")
+	config.DEBT_SERVICE_HOST = l.string("DEBT_SERVICE_HOST", "debts-service")
+
+	defaults := l.backend("GRPC", Backend{
+		Timeout:           5 * time.Second,
+		Retries:           2,
+		RetryBackoff:      100 * time.Millisecond,
+		RetryBackoffMax:   time.Second,
+		RetryMethods:      []string{"Get", "List", "Total"},
+		BreakerFailures:   5,
+		BreakerCooldown:   30 * time.Second,
+		TLS:               "insecure",
+		ResolveInterval:   10 * time.Second,
+		Balancer:          "round_robin",
+		OutlierFailures:   5,
+		OutlierEjection:   30 * time.Second,
+		MaxEjectedPercent: 50,
+	})
+	config.USER_SERVICE_CLIENT = l.backend("USER_SERVICE", defaults)
+	config.USER_SERVICE_CLIENT.Addresses = l.list("USER_SERVICE_ADDRESSES", config.UserServiceAddr())
+	l.routing("USER_SERVICE", &config.USER_SERVICE_CLIENT)
+	config.PRODUCT_SERVICE_CLIENT = l.backend("PRODUCT_SERVICE", defaults)
+	config.PRODUCT_SERVICE_CLIENT.Addresses = l.list("PRODUCT_SERVICE_ADDRESSES", config.ProductServiceAddr())
+	l.routing("PRODUCT_SERVICE", &config.PRODUCT_SERVICE_CLIENT)
+	config.DEBT_SERVICE_CLIENT = l.backend("DEBT_SERVICE", defaults)
+	config.DEBT_SERVICE_CLIENT.Addresses = l.list("DEBT_SERVICE_ADDRESSES", config.DebtServiceAddr())
+	l.routing("DEBT_SERVICE", &config.DEBT_SERVICE_CLIENT)
+
+	config.REFRESH_TOKEN = l.required("REFRESH_TOKEN")
+	config.ACCESS_TOKEN = l.required("ACCESS_TOKEN")
+	config.EXPIRED_ACCESS = l.positive("EXPIRED_ACCESS", 6)
+	config.EXPIRED_REFRESH = l.positive("EXPIRED_REFRESH", 168)
+	config.REFRESH_REUSE_GRACE = l.nonNegative("REFRESH_REUSE_GRACE", 10)
+
+	config.JWT_ALGORITHM = l.oneOf("JWT_ALGORITHM", "HS256", "HS256", "RS256", "EdDSA")
+	config.JWT_KEYS_DIR = l.string("JWT_KEYS_DIR", "keys")
+	config.JWT_KEY_ROTATION = l.int("JWT_KEY_ROTATION", 0)
+	config.JWT_ACCEPT_HS256 = l.bool("JWT_ACCEPT_HS256", false)
+
+	config.BRANCH_ASSIGNMENTS_FILE = l.string("BRANCH_ASSIGNMENTS_FILE", "data/branch_assignments.json")
+	config.BRANCH_CACHE_TTL = l.positive("BRANCH_CACHE_TTL", 60)
+	config.BRANCH_ALLOW_UNASSIGNED = l.bool("BRANCH_ALLOW_UNASSIGNED", false)
+
+	config.CASBIN_COMPANY_POLICY = l.string("CASBIN_COMPANY_POLICY", "data/company_policy.csv")
+	config.CASBIN_POLICY_VERSIONS = l.string("CASBIN_POLICY_VERSIONS", "data/policy_versions")
+	config.CASBIN_POLICY_VERSIONS_KEEP = l.positive("CASBIN_POLICY_VERSIONS_KEEP", 50)
+	config.CASBIN_WATCH_INTERVAL = l.positive("CASBIN_WATCH_INTERVAL", 5)
+	config.POLICY_CHECK = l.oneOf("POLICY_CHECK", "strict", "strict", "warn", "off")
+
+	config.SALE_EDIT_WINDOW = l.positive("SALE_EDIT_WINDOW", 60)
+	config.BACKEND_TIMEZONE = l.location("BACKEND_TIMEZONE", "Local")
+
+	config.MINIO_ENDPOINT = l.string("MINIO_ENDPOINT", "minio.smartadmin.uz")
+	config.MINIO_ACCESS_KEY = l.required("MINIO_ACCESS_KEY")
+	config.MINIO_SECRET_KEY = l.required("MINIO_SECRET_KEY")
+	config.MINIO_USE_SSL = l.bool("MINIO_USE_SSL", true)
+	config.MINIO_BUCKET = l.string("MINIO_BUCKET", "media")
+
+	config.KAFKA_BROKERS = l.requiredList("KAFKA_BROKERS")
+	config.KAFKA_LOG_TOPIC = l.string("KAFKA_LOG_TOPIC", "logs")
+	config.KAFKA_LOG_LEVEL = l.oneOf("KAFKA_LOG_LEVEL", "info", logLevels...)
+	config.KAFKA_LOG_BUFFER = l.positive("KAFKA_LOG_BUFFER", 1024)
+	config.KAFKA_LOG_FALLBACK_FILE = l.string("KAFKA_LOG_FALLBACK_FILE", "kafka-fallback.log")
+	config.LOG_DIR = l.string("LOG_DIR", ".")
+	config.LOG_FILE = l.string("LOG_FILE", "app.log")
+	config.LOG_LEVEL = l.oneOf("LOG_LEVEL", "debug", logLevels...)
+	config.LOG_MAX_SIZE = l.positive("LOG_MAX_SIZE", 100)
+	config.LOG_MAX_AGE = l.nonNegative("LOG_MAX_AGE", 24)
+	config.LOG_MAX_BACKUPS = l.nonNegative("LOG_MAX_BACKUPS", 7)
+	config.LOG_COMPRESS = l.bool("LOG_COMPRESS", true)
+	for _, file := range []*string{&config.LOG_FILE, &config.KAFKA_LOG_FALLBACK_FILE} {
+		if !filepath.IsAbs(*file) {
+			*file = filepath.Join(config.LOG_DIR, *file)
+		}
+	}
+	config.LOG_REDACT_KEYS = l.list("LOG_REDACT_KEYS", "password,token,secret,authorization,phone,email")
+	config.LOG_REDACT_PATTERNS = l.list("LOG_REDACT_PATTERNS", "phone,email,token,secret")
+
+	config.SWAGGER_USER = l.string("SWAGGER_USER", "smart-admin")
+	config.SWAGGER_PASSWORD = l.required("SWAGGER_PASSWORD")
+
+	config.HEALTH_PROBE_TIMEOUT = l.positive("HEALTH_PROBE_TIMEOUT", 2)
+	config.READINESS_CRITICAL = l.list("READINESS_CRITICAL", "user,product,company,debt")
+	for _, name := range config.READINESS_CRITICAL {
+		if !slices.Contains(Dependencies, name) {
+			l.errs = append(l.errs, fmt.Errorf("READINESS_CRITICAL: unknown dependency %q, expected one of %s", name, strings.Join(Dependencies, ", ")))
+		}
+	}
+
+	config.ACCESS_LOG_SAMPLE = l.percentages("ACCESS_LOG_SAMPLE", "/healthz=0,/readyz=0")
+
+	config.AUDIT_KAFKA_TOPIC = l.string("AUDIT_KAFKA_TOPIC", "audit")
+	config.AUDIT_FALLBACK_FILE = l.string("AUDIT_FALLBACK_FILE", "audit-fallback.log")
+	config.AUDIT_FILE = l.string("AUDIT_FILE", "audit.log")
+	config.AUDIT_RECENT = l.positive("AUDIT_RECENT", 1000)
+	config.AUDIT_MAX_PAYLOAD = l.positive("AUDIT_MAX_PAYLOAD", 16<<10)
+
+	config.TRACING_EXPORTER = l.oneOf("TRACING_EXPORTER", "none", "none", "otlp", "stdout", "file")
+	config.TRACING_SERVICE_NAME = l.string("TRACING_SERVICE_NAME", "api-gateway")
+	config.TRACING_OTLP_ENDPOINT = l.string("TRACING_OTLP_ENDPOINT", "localhost:4317")
+	config.TRACING_OTLP_INSECURE = l.bool("TRACING_OTLP_INSECURE", true)
+	config.TRACING_FILE = l.string("TRACING_FILE", "traces.json")
+	config.TRACING_SAMPLE_PERCENT = l.nonNegative("TRACING_SAMPLE_PERCENT", 100)
+	if config.TRACING_SAMPLE_PERCENT > 100 {
+		l.errs = append(l.errs, fmt.Errorf("TRACING_SAMPLE_PERCENT must be at most 100, got %d", config.TRACING_SAMPLE_PERCENT))
+	}
+
+	if err := errors.Join(l.errs...); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return &config, nil
+}
+
+// UserServiceAddr is the address of the auth and company service.
+func (c *Config) UserServiceAddr() string {
+	return c.USER_SERVICE_HOST + c.USER_SERVICE
+}
+
+// ProductServiceAddr is the address of the product service.
+func (c *Config) ProductServiceAddr() string {
+	return c.PRODUCT_SERVICE_HOST + c.PRODUCT_SERVICE
+}
+
+// DebtServiceAddr is the address of the debt service.
+func (c *Config) DebtServiceAddr() string {
+	return c.DEBT_SERVICE_HOST + c.DEBT_SERVICE
+}
+
+// loader reads typed variables and collects every problem it finds.
+type loader struct {
+	file map[string]string
+	errs []error
+}
+
+// lookup returns the value of key, or the contents of the file named by
+// key_FILE, from the environment and else from the config file.
+func (l *loader) lookup(key string) (string, bool) {
+	sources := []func(string) (string, bool){
+		os.LookupEnv,
+		func(key string) (string, bool) {
+			value, ok := l.file[key]
+			return value, ok
+		},
+	}
+
+	for _, get := range sources {
+		if value, ok := get(key); ok {
+			return value, true
+		}
 
-	config.REFRESH_TOKEN = cast.ToString(Coalesce("REFRESH_TOKEN", "secret"))
-	config.ACCESS_TOKEN = cast.ToString(Coalesce("ACCESS_TOKEN", "secret"))
-	config.EXPIRED_ACCESS = cast.ToString(Coalesce("EXPIRED_ACCESS", "6"))
+		if path, ok := get(key + "_FILE"); ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				l.errs = append(l.errs, fmt.Errorf("%s_FILE: %w", key, err))
+				return "", false
+			}
+			return strings.TrimSpace(string(data)), true
+		}
+	}
 
-	return &config
+	return "", false
 }
 
-func Coalesce(key string, defaultValue interface{}) interface{} {
-	if value, ok := os.LookupEnv(key); ok {
+func (l *loader) string(key, defaultValue string) string {
+	if value, ok := l.lookup(key); ok {
 		return value
 	}
 	return defaultValue
 }
+
+func (l *loader) required(key string) string {
+	value, _ := l.lookup(key)
+	if value == "" {
+		l.errs = append(l.errs, fmt.Errorf("%s is required", key))
+	}
+	return value
+}
+
+func (l *loader) int(key string, defaultValue int) int {
+	value, ok := l.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+
+	n, err := cast.ToIntE(strings.TrimSpace(value))
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s must be a number, got %q", key, value))
+	}
+	return n
+}
+
+func (l *loader) positive(key string, defaultValue int) int {
+	errs := len(l.errs)
+	n := l.int(key, defaultValue)
+	if n <= 0 && len(l.errs) == errs {
+		l.errs = append(l.errs, fmt.Errorf("%s must be greater than zero", key))
+	}
+	return n
+}
+
+func (l *loader) bool(key string, defaultValue bool) bool {
+	value, ok := l.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+
+	b, err := cast.ToBoolE(strings.TrimSpace(value))
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s must be true or false, got %q", key, value))
+	}
+	return b
+}
+
+// nonNegative reads a number that may be zero.
+func (l *loader) nonNegative(key string, defaultValue int) int {
+	errs := len(l.errs)
+	n := l.int(key, defaultValue)
+	if n < 0 && len(l.errs) == errs {
+		l.errs = append(l.errs, fmt.Errorf("%s must not be negative", key))
+	}
+	return n
+}
+
+// millis reads a positive duration given in milliseconds.
+func (l *loader) millis(key string, defaultValue time.Duration) time.Duration {
+	return time.Millisecond * time.Duration(l.positive(key, int(defaultValue.Milliseconds())))
+}
+
+// location reads a time zone name such as Asia/Tashkent, UTC or Local.
+func (l *loader) location(key, defaultValue string) *time.Location {
+	value := l.string(key, defaultValue)
+	location, err := time.LoadLocation(value)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s must be a time zone name, got %q", key, value))
+		return time.UTC
+	}
+	return location
+}
+
+// backend reads the client settings <prefix>_TIMEOUT, <prefix>_RETRIES,
+// <prefix>_RETRY_BACKOFF, <prefix>_RETRY_BACKOFF_MAX, <prefix>_RETRY_METHODS,
+// <prefix>_BREAKER_FAILURES, <prefix>_BREAKER_COOLDOWN, <prefix>_TLS,
+// <prefix>_TLS_CA, <prefix>_TLS_CERT, <prefix>_TLS_KEY,
+// <prefix>_TLS_SERVER_NAME, <prefix>_ENDPOINTS_FILE,
+// <prefix>_RESOLVE_INTERVAL, <prefix>_BALANCER, <prefix>_OUTLIER_FAILURES,
+// <prefix>_OUTLIER_EJECTION and <prefix>_MAX_EJECTED_PERCENT. Durations are
+// in milliseconds.
+func (l *loader) backend(prefix string, defaults Backend) Backend {
+	b := Backend{
+		Timeout:           l.millis(prefix+"_TIMEOUT", defaults.Timeout),
+		Retries:           l.nonNegative(prefix+"_RETRIES", defaults.Retries),
+		RetryBackoff:      l.millis(prefix+"_RETRY_BACKOFF", defaults.RetryBackoff),
+		RetryBackoffMax:   l.millis(prefix+"_RETRY_BACKOFF_MAX", defaults.RetryBackoffMax),
+		RetryMethods:      l.list(prefix+"_RETRY_METHODS", strings.Join(defaults.RetryMethods, ",")),
+		BreakerFailures:   l.nonNegative(prefix+"_BREAKER_FAILURES", defaults.BreakerFailures),
+		BreakerCooldown:   l.millis(prefix+"_BREAKER_COOLDOWN", defaults.BreakerCooldown),
+		TLS:               l.oneOf(prefix+"_TLS", defaults.TLS, "insecure", "tls", "mtls"),
+		TLSCA:             l.string(prefix+"_TLS_CA", defaults.TLSCA),
+		TLSCert:           l.string(prefix+"_TLS_CERT", defaults.TLSCert),
+		TLSKey:            l.string(prefix+"_TLS_KEY", defaults.TLSKey),
+		TLSServerName:     l.string(prefix+"_TLS_SERVER_NAME", defaults.TLSServerName),
+		EndpointsFile:     l.string(prefix+"_ENDPOINTS_FILE", defaults.EndpointsFile),
+		ResolveInterval:   l.millis(prefix+"_RESOLVE_INTERVAL", defaults.ResolveInterval),
+		Balancer:          l.oneOf(prefix+"_BALANCER", defaults.Balancer, "round_robin", "least_request"),
+		OutlierFailures:   l.nonNegative(prefix+"_OUTLIER_FAILURES", defaults.OutlierFailures),
+		OutlierEjection:   l.millis(prefix+"_OUTLIER_EJECTION", defaults.OutlierEjection),
+		MaxEjectedPercent: l.nonNegative(prefix+"_MAX_EJECTED_PERCENT", defaults.MaxEjectedPercent),
+	}
+
+	if b.MaxEjectedPercent > 100 {
+		l.errs = append(l.errs, fmt.Errorf("%s_MAX_EJECTED_PERCENT must not be more than 100", prefix))
+	}
+
+	if b.TLS == "mtls" && (b.TLSCert == "" || b.TLSKey == "") {
+		l.errs = append(l.errs, fmt.Errorf("%s_TLS_CERT and %s_TLS_KEY are required with %s_TLS=mtls", prefix, prefix, prefix))
+	}
+
+	if b.RetryBackoffMax < b.RetryBackoff {
+		l.errs = append(l.errs, fmt.Errorf("%s_RETRY_BACKOFF_MAX must not be less than %s_RETRY_BACKOFF", prefix, prefix))
+	}
+
+	return b
+}
+
+// routing reads the canary and shadow settings of one service:
+// <prefix>_CANARY_ADDRESSES, <prefix>_CANARY_WEIGHTS (e.g.
+// "GetProduct=20,*=5"), <prefix>_CANARY_COMPANIES,
+// <prefix>_SHADOW_ADDRESSES and <prefix>_SHADOW_PERCENT.
+func (l *loader) routing(prefix string, b *Backend) {
+	b.CanaryAddresses = l.list(prefix+"_CANARY_ADDRESSES", "")
+	b.CanaryWeights = l.percentages(prefix+"_CANARY_WEIGHTS", "")
+	b.CanaryCompanies = l.list(prefix+"_CANARY_COMPANIES", "")
+	b.ShadowAddresses = l.list(prefix+"_SHADOW_ADDRESSES", "")
+	b.ShadowPercent = l.nonNegative(prefix+"_SHADOW_PERCENT", 100)
+
+	if b.ShadowPercent > 100 {
+		l.errs = append(l.errs, fmt.Errorf("%s_SHADOW_PERCENT must not be more than 100", prefix))
+	}
+	if len(b.CanaryAddresses) == 0 && (len(b.CanaryWeights) > 0 || len(b.CanaryCompanies) > 0) {
+		l.errs = append(l.errs, fmt.Errorf("%s_CANARY_WEIGHTS and %s_CANARY_COMPANIES need %s_CANARY_ADDRESSES", prefix, prefix, prefix))
+	}
+}
+
+// percentages reads a comma-separated list of name=percent pairs.
+func (l *loader) percentages(key, defaultValue string) map[string]int {
+	weights := make(map[string]int)
+	for _, pair := range l.list(key, defaultValue) {
+		name, value, ok := strings.Cut(pair, "=")
+		n, err := cast.ToIntE(strings.TrimSpace(value))
+		if !ok || err != nil || n < 0 || n > 100 {
+			l.errs = append(l.errs, fmt.Errorf("%s: %q must be name=percent with a percent from 0 to 100", key, pair))
+			continue
+		}
+		weights[strings.TrimSpace(name)] = n
+	}
+	return weights
+}
+
+// list reads a comma-separated list.
+func (l *loader) list(key, defaultValue string) []string {
+	return split(l.string(key, defaultValue))
+}
+
+// requiredList reads a comma-separated list that must not be empty.
+func (l *loader) requiredList(key string) []string {
+	return split(l.required(key))
+}
+
+func split(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (l *loader) oneOf(key, defaultValue string, allowed ...string) string {
+	value := l.string(key, defaultValue)
+	for _, a := range allowed {
+		if value == a {
+			return value
+		}
+	}
+
+	l.errs = append(l.errs, fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), value))
+	return value
+}
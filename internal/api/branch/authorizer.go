@@ -0,0 +1,176 @@
+package branch
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	pbc "gateway/internal/generated/company"
+)
+
+// listPageSize is the page size used when loading all branches of a company.
+const listPageSize = 100
+
+// unrestrictedRoles may address every branch of their company regardless of
+// assignments. Admins manage all companies and skip branch checks entirely.
+var unrestrictedRoles = map[string]bool{
+	"owner": true,
+}
+
+// Authorizer resolves the branches a caller may address and caches the
+// branch list of each company.
+type Authorizer struct {
+	client      pbc.CompanyServiceClient
+	assignments AssignmentStore
+	ttl         time.Duration
+	// allowUnassigned lets users without an assignment address every branch.
+	allowUnassigned bool
+
+	mu    sync.Mutex
+	cache map[string]cachedBranches
+}
+
+type cachedBranches struct {
+	ids       []string
+	expiresAt time.Time
+}
+
+func NewAuthorizer(client pbc.CompanyServiceClient, assignments AssignmentStore, ttl time.Duration, allowUnassigned bool) *Authorizer {
+	return &Authorizer{
+		client:          client,
+		assignments:     assignments,
+		ttl:             ttl,
+		allowUnassigned: allowUnassigned,
+		cache:           make(map[string]cachedBranches),
+	}
+}
+
+// Assignments returns the store holding per-user branch assignments.
+func (a *Authorizer) Assignments() AssignmentStore {
+	return a.assignments
+}
+
+// AllowsUnassigned reports whether users without an assignment may address
+// every branch rather than none.
+func (a *Authorizer) AllowsUnassigned() bool {
+	return a.allowUnassigned
+}
+
+// Allowed returns the branches of the company the user may work in. Owners
+// get every branch. Other roles get their assigned branches; without an
+// assignment they get none, unless the authorizer allows unassigned users.
+func (a *Authorizer) Allowed(ctx context.Context, companyID, userID, role string) ([]string, error) {
+	branches, err := a.CompanyBranches(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if unrestrictedRoles[role] {
+		return branches, nil
+	}
+
+	assigned, ok, err := a.assignments.Get(ctx, companyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if a.allowUnassigned {
+			return branches, nil
+		}
+		return []string{}, nil
+	}
+
+	allowed := make([]string, 0, len(assigned))
+	for _, id := range assigned {
+		if slices.Contains(branches, id) {
+			allowed = append(allowed, id)
+		}
+	}
+
+	return allowed, nil
+}
+
+// Restricted reports whether the user is limited to some branches of the
+// company and so may not read company-wide data by leaving the branch out.
+func (a *Authorizer) Restricted(ctx context.Context, companyID, userID, role string) (bool, error) {
+	if role == "admin" || unrestrictedRoles[role] {
+		return false, nil
+	}
+
+	_, ok, err := a.assignments.Get(ctx, companyID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return ok || !a.allowUnassigned, nil
+}
+
+// Check reports whether the user may address branchID.
+func (a *Authorizer) Check(ctx context.Context, companyID, userID, role, branchID string) (bool, error) {
+	if role == "admin" {
+		return true, nil
+	}
+
+	allowed, err := a.Allowed(ctx, companyID, userID, role)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Contains(allowed, branchID), nil
+}
+
+// InCompany reports whether branchID belongs to the company.
+func (a *Authorizer) InCompany(ctx context.Context, companyID, branchID string) (bool, error) {
+	branches, err := a.CompanyBranches(ctx, companyID)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Contains(branches, branchID), nil
+}
+
+// CompanyBranches returns the IDs of all branches of the company.
+func (a *Authorizer) CompanyBranches(ctx context.Context, companyID string) ([]string, error) {
+	a.mu.Lock()
+	cached, ok := a.cache[companyID]
+	a.mu.Unlock()
+
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.ids, nil
+	}
+
+	var ids []string
+	for page := int32(1); ; page++ {
+		res, err := a.client.ListBranches(ctx, &pbc.ListBranchesRequest{
+			CompanyId: companyID,
+			Limit:     listPageSize,
+			Page:      page,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, b := range res.Branches {
+			ids = append(ids, b.BranchId)
+		}
+
+		if len(res.Branches) < listPageSize || int64(len(ids)) >= res.TotalCount {
+			break
+		}
+	}
+
+	a.mu.Lock()
+	a.cache[companyID] = cachedBranches{ids: ids, expiresAt: time.Now().Add(a.ttl)}
+	a.mu.Unlock()
+
+	return ids, nil
+}
+
+// Invalidate drops the cached branches of the company, e.g. after a branch
+// was created or deleted.
+func (a *Authorizer) Invalidate(companyID string) {
+	a.mu.Lock()
+	delete(a.cache, companyID)
+	a.mu.Unlock()
+}
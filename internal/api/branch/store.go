@@ -0,0 +1,108 @@
+package branch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// AssignmentStore keeps which branches a user of a company may work in.
+// Backends have no notion of this yet, so the gateway owns it.
+type AssignmentStore interface {
+	// Get returns the branches assigned to the user and whether an
+	// assignment exists at all.
+	Get(ctx context.Context, companyID, userID string) ([]string, bool, error)
+	// Set replaces the user's assignment.
+	Set(ctx context.Context, companyID, userID string, branchIDs []string) error
+	// Delete removes the user's assignment.
+	Delete(ctx context.Context, companyID, userID string) error
+}
+
+// FileStore is an AssignmentStore kept in memory and persisted as JSON.
+// An empty path keeps it in memory only.
+type FileStore struct {
+	path string
+
+	mu sync.RWMutex
+	// assignments is keyed by company ID, then user ID.
+	assignments map[string]map[string][]string
+}
+
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		path:        path,
+		assignments: make(map[string]map[string][]string),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.assignments); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileStore) Get(_ context.Context, companyID, userID string) ([]string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids, ok := s.assignments[companyID][userID]
+	return append([]string(nil), ids...), ok, nil
+}
+
+func (s *FileStore) Set(_ context.Context, companyID, userID string, branchIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.assignments[companyID] == nil {
+		s.assignments[companyID] = make(map[string][]string)
+	}
+	s.assignments[companyID][userID] = append([]string{}, branchIDs...)
+
+	return s.save()
+}
+
+func (s *FileStore) Delete(_ context.Context, companyID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.assignments[companyID], userID)
+
+	return s.save()
+}
+
+// save writes the assignments atomically. It must be called with the lock held.
+func (s *FileStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.assignments, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -15,6 +15,26 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Public keys for verifying access tokens issued by the gateway, selected by the token's kid header.\nThe set is empty while tokens are signed with the shared HS256 secret.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "JSON Web Key Set",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/token.JWKS"
+                        }
+                    }
+                }
+            }
+        },
         "/adjustment": {
             "get": {
                 "security": [
@@ -266,6 +286,93 @@ const docTemplate = `{
                 }
             }
         },
+        "/audit": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Recent POST, PUT and DELETE calls made in the caller's company, newest first, with who made them, on which record and how they ended",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "List audit events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user who made the call",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "POST",
+                            "PUT",
+                            "PATCH",
+                            "DELETE"
+                        ],
+                        "type": "string",
+                        "description": "HTTP method",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Route template, e.g. /sales/:id",
+                        "name": "route",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the record the call addressed",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest time, RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest time (exclusive), RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of events to return (default 50, at most 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/audit.Event"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
         "/branches/create": {
             "post": {
                 "security": [
@@ -305,10 +412,7 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -356,10 +460,7 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -402,10 +503,7 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -455,10 +553,7 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -499,10 +594,7 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -589,13 +681,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid input parameters or missing required values",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -626,7 +718,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/products.CashFlowRequest"
+                            "$ref": "#/definitions/entity.CashFlowRequest"
                         }
                     },
                     {
@@ -647,13 +739,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -684,7 +776,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/products.CashFlowRequest"
+                            "$ref": "#/definitions/entity.CashFlowRequest"
                         }
                     },
                     {
@@ -705,13 +797,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -917,7 +1009,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Client"
+                            "$ref": "#/definitions/entity.ClientUpdate"
                         }
                     }
                 ],
@@ -934,6 +1026,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/entity.Error"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1018,7 +1116,7 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -1061,7 +1159,7 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -1106,7 +1204,7 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -1157,7 +1255,7 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -1200,7 +1298,7 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -1250,7 +1348,7 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -1349,7 +1447,7 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -1399,20 +1497,20 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/companies/users": {
+        "/companies/permissions": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get all users for a company",
+                "description": "List the routes an owner may grant to company roles",
                 "consumes": [
                     "application/json"
                 ],
@@ -1420,43 +1518,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "companies"
+                    "Roles"
                 ],
-                "summary": "ListCompanyUsers",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Page",
-                        "name": "page",
-                        "in": "query"
+                "summary": "Permission catalog",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rbac.Permission"
+                            }
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/roles": {
+            "get": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Name",
-                        "name": "name",
-                        "in": "query"
+                        "ApiKeyAuth": []
                     }
                 ],
+                "description": "List the roles defined by the company",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "List roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/company.ListCompanyUsersResponse"
+                                "$ref": "#/definitions/rbac.Role"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -1467,7 +1582,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a new user for a company",
+                "description": "Create a company role from catalog permissions, optionally inheriting from worker or another company role",
                 "consumes": [
                     "application/json"
                 ],
@@ -1475,44 +1590,56 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "companies"
+                    "Roles"
                 ],
-                "summary": "Create Company User",
+                "summary": "Create role",
                 "parameters": [
                     {
-                        "description": "Company user details",
-                        "name": "input",
+                        "description": "Role",
+                        "name": "Role",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.CreateUserToCompanyRequest"
+                            "$ref": "#/definitions/rbac.Role"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/company.Message"
+                            "$ref": "#/definitions/rbac.Role"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/creditor": {
-            "get": {
+        "/companies/roles/{role}": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve a list of creditor records with optional filters.",
+                "description": "Replace the permissions and parents of a company role",
                 "consumes": [
                     "application/json"
                 ],
@@ -1520,75 +1647,61 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Creditor"
+                    "Roles"
                 ],
-                "summary": "List creditor records",
+                "summary": "Update role",
                 "parameters": [
-                    {
-                        "type": "boolean",
-                        "description": "Filter by fully paid status",
-                        "name": "is_fully_pay",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by currency code",
-                        "name": "currency_code",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "Filter by description",
-                        "name": "description",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Filter by unpaid credits",
-                        "name": "no_paid_credits",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Maximum results",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Role name",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Page number for pagination",
-                        "name": "page",
-                        "in": "query"
+                        "description": "Role",
+                        "name": "Role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rbac.Role"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of creditor records",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/debts.DebtsList"
+                            "$ref": "#/definitions/rbac.Role"
                         }
                     },
                     "400": {
-                        "description": "Invalid filter value",
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
-                        "description": "Server error",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a new creditor record.",
+                "description": "Delete a company role and unassign it from every user",
                 "consumes": [
                     "application/json"
                 ],
@@ -1596,50 +1709,683 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Creditor"
+                    "Roles"
                 ],
-                "summary": "Create creditor",
+                "summary": "Delete role",
                 "parameters": [
                     {
-                        "description": "Creditor details",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.DebtsRequest"
-                        }
+                        "type": "string",
+                        "description": "Role name",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/company.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/users": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get all users for a company",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "ListCompanyUsers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name",
+                        "name": "name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/company.ListCompanyUsersResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new user for a company",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Create Company User",
+                "parameters": [
+                    {
+                        "description": "Company user details",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.CreateUserToCompanyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/company.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/users/{user_id}/branches": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the branches a user of the company may work in. Users without an assignment may work in no branch, unless BRANCH_ALLOW_UNASSIGNED is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Branches"
+                ],
+                "summary": "Get User Branches",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.UserBranches"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Restrict a user of the company to the given branches. An empty list removes the assignment, which leaves the user no branch unless BRANCH_ALLOW_UNASSIGNED is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Branches"
+                ],
+                "summary": "Set User Branches",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Branch IDs",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.UserBranchesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.UserBranches"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/users/{user_id}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the company roles assigned to a user. A user without company roles is authorized by the role in their token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Get user roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.UserRoles"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace the company roles of a user of the company. An empty list restores the role in their token.\nThe user ID cannot name a built-in role (admin, owner, worker) or a company role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Set user roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role names",
+                        "name": "Roles",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.UserRolesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.UserRoles"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/creditor": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve a list of creditor records with optional filters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Creditor"
+                ],
+                "summary": "List creditor records",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Filter by fully paid status",
+                        "name": "is_fully_pay",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by currency code",
+                        "name": "currency_code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by description",
+                        "name": "description",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by unpaid credits",
+                        "name": "no_paid_credits",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum results",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of creditor records",
+                        "schema": {
+                            "$ref": "#/definitions/debts.DebtsList"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter value",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new creditor record.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Creditor"
+                ],
+                "summary": "Create creditor",
+                "parameters": [
+                    {
+                        "description": "Creditor details",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.DebtsRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created creditor record",
                         "schema": {
-                            "$ref": "#/definitions/debts.Debts"
+                            "$ref": "#/definitions/debts.Debts"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/creditor/pay": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Make a payment toward a creditor record.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Creditor"
+                ],
+                "summary": "Process creditor payment",
+                "parameters": [
+                    {
+                        "description": "Payment details",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.PayDebtReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated creditor record",
+                        "schema": {
+                            "$ref": "#/definitions/debts.Debts"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/creditor/pay/{supplier_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve all payments made to a specific supplier for creditor records.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Creditor"
+                ],
+                "summary": "List payments to supplier",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Supplier ID",
+                        "name": "supplier_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Supplier payment records",
+                        "schema": {
+                            "$ref": "#/definitions/debts.UserPaymentsRes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid supplier ID",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/creditor/payment/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve details for a specific creditor payment.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Creditor"
+                ],
+                "summary": "Get creditor payment details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payment details",
+                        "schema": {
+                            "$ref": "#/definitions/debts.Payment"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid payment ID",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/creditor/payments/{credit_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve all payments for a specific creditor record.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Creditor"
+                ],
+                "summary": "List payments by creditor ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Creditor ID",
+                        "name": "credit_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of payments",
+                        "schema": {
+                            "$ref": "#/definitions/debts.PaymentList"
                         }
                     },
                     "400": {
-                        "description": "Invalid input",
+                        "description": "Invalid creditor ID format",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/creditor/pay": {
-            "post": {
+        "/creditor/supplier/{supplier_id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Make a payment toward a creditor record.",
+                "description": "Retrieve creditor records for a specific supplier.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1649,48 +2395,86 @@ const docTemplate = `{
                 "tags": [
                     "Creditor"
                 ],
-                "summary": "Process creditor payment",
+                "summary": "Get credits records for supplier",
                 "parameters": [
                     {
-                        "description": "Payment details",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "Supplier ID",
+                        "name": "supplier_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Creditor records for the supplier",
+                        "schema": {
+                            "$ref": "#/definitions/debts.DebtsList"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid supplier ID",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
                         "schema": {
-                            "$ref": "#/definitions/debts.PayDebtsReq"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
+                }
+            }
+        },
+        "/creditor/total-sum": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve the total amount of creditor records for the company.",
+                "consumes": [
+                    "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Creditor"
+                ],
+                "summary": "Get total creditor sum",
                 "responses": {
                     "200": {
-                        "description": "Updated creditor record",
+                        "description": "Total creditor sum",
                         "schema": {
-                            "$ref": "#/definitions/debts.Debts"
+                            "$ref": "#/definitions/debts.SumMoney"
                         }
                     },
                     "400": {
-                        "description": "Invalid input",
+                        "description": "Bad request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/creditor/pay/{supplier_id}": {
+        "/creditor/total-sum/{supplier_id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve all payments made to a specific supplier for creditor records.",
+                "description": "Retrieve the total creditor amount for a specific supplier.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1700,7 +2484,7 @@ const docTemplate = `{
                 "tags": [
                     "Creditor"
                 ],
-                "summary": "List payments to supplier",
+                "summary": "Get supplier's total creditor sum",
                 "parameters": [
                     {
                         "type": "string",
@@ -1711,35 +2495,211 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Supplier payment records",
+                    "200": {
+                        "description": "Supplier's total creditor sum",
+                        "schema": {
+                            "$ref": "#/definitions/debts.SumMoney"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid supplier ID",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/creditor/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve creditor details using its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Creditor"
+                ],
+                "summary": "Get creditor by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Creditor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Creditor details",
+                        "schema": {
+                            "$ref": "#/definitions/debts.Debts"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/debts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve debtor records with optional filters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Debts"
+                ],
+                "summary": "List debtor records",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Filter by fully paid status",
+                        "name": "is_fully_pay",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by currency code",
+                        "name": "currency_code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by description",
+                        "name": "description",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by unpaid debts",
+                        "name": "no_paid_debts",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum results",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of debtor records",
+                        "schema": {
+                            "$ref": "#/definitions/debts.DebtsList"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter value",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new debtor record.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Debts"
+                ],
+                "summary": "Create debtor",
+                "parameters": [
+                    {
+                        "description": "Debtor details",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.DebtsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created debtor record",
                         "schema": {
-                            "$ref": "#/definitions/debts.UserPaymentsRes"
+                            "$ref": "#/definitions/debts.Debts"
                         }
                     },
                     "400": {
-                        "description": "Invalid supplier ID",
+                        "description": "Invalid input",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/creditor/payment/{id}": {
+        "/debts/client/{client_id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve details for a specific creditor payment.",
+                "description": "Retrieve debtor records for a specific client.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1747,97 +2707,91 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Creditor"
+                    "Debts"
                 ],
-                "summary": "Get creditor payment details",
+                "summary": "Get client debtor records",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Payment ID",
-                        "name": "id",
+                        "description": "Client ID",
+                        "name": "client_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Payment details",
+                        "description": "Debtor records for the client",
                         "schema": {
-                            "$ref": "#/definitions/debts.Payment"
+                            "$ref": "#/definitions/debts.DebtsList"
                         }
                     },
                     "400": {
-                        "description": "Invalid payment ID",
+                        "description": "Invalid client ID",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/creditor/payments/{credit_id}": {
+        "/debts/excel/{currency}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve all payments for a specific creditor record.",
+                "description": "Export debtor records as an Excel file filtered by currency.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
                 ],
                 "tags": [
-                    "Creditor"
+                    "Debts"
                 ],
-                "summary": "List payments by creditor ID",
+                "summary": "Export debts to Excel",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Creditor ID",
-                        "name": "credit_id",
+                        "description": "Currency code",
+                        "name": "currency",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of payments",
-                        "schema": {
-                            "$ref": "#/definitions/debts.PaymentList"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid creditor ID format",
+                        "description": "Excel file with debtor records",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "type": "file"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/creditor/supplier/{supplier_id}": {
-            "get": {
+        "/debts/pay": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve creditor records for a specific supplier.",
+                "description": "Make a payment toward a debtor record.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1845,48 +2799,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Creditor"
+                    "Debts"
                 ],
-                "summary": "Get credits records for supplier",
+                "summary": "Process debtor payment",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Supplier ID",
-                        "name": "supplier_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Payment details",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.PayDebtReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Creditor records for the supplier",
+                        "description": "Updated debtor record",
                         "schema": {
-                            "$ref": "#/definitions/debts.DebtsList"
+                            "$ref": "#/definitions/debts.Debts"
                         }
                     },
                     "400": {
-                        "description": "Invalid supplier ID",
+                        "description": "Invalid input",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/creditor/total-sum": {
+        "/debts/payment/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve the total amount of creditor records for the company.",
+                "description": "Retrieve details for a specific payment.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1894,39 +2850,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Creditor"
+                    "Debts"
+                ],
+                "summary": "Get payment details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get total creditor sum",
                 "responses": {
                     "200": {
-                        "description": "Total creditor sum",
+                        "description": "Payment details",
                         "schema": {
-                            "$ref": "#/definitions/debts.SumMoney"
+                            "$ref": "#/definitions/debts.Payment"
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Invalid payment ID",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/creditor/total-sum/{supplier_id}": {
-            "get": {
+        "/debts/payments": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve the total creditor amount for a specific supplier.",
+                "description": "Retrieve a list of payments",
                 "consumes": [
                     "application/json"
                 ],
@@ -1934,48 +2899,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Creditor"
+                    "Payments"
                 ],
-                "summary": "Get supplier's total creditor sum",
+                "summary": "Bought products for cash or debt",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Supplier ID",
-                        "name": "supplier_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Sale data",
+                        "name": "Sale",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.PaymentSale"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Supplier's total creditor sum",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/debts.SumMoney"
+                            "$ref": "#/definitions/products.SaleResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid supplier ID",
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
-                        "description": "Server error",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/creditor/{id}": {
+        "/debts/payments/{debt_id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve creditor details using its ID.",
+                "description": "Retrieve all payments for a specific debtor record.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1983,48 +2950,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Creditor"
+                    "Debts"
                 ],
-                "summary": "Get creditor by ID",
+                "summary": "List payments by debtor ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Creditor ID",
-                        "name": "id",
+                        "description": "Debtor ID",
+                        "name": "debt_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Creditor details",
+                        "description": "List of payments",
                         "schema": {
-                            "$ref": "#/definitions/debts.Debts"
+                            "$ref": "#/definitions/debts.PaymentList"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid debtor ID",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/debts": {
+        "/debts/payments/{user_id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve debtor records with optional filters.",
+                "description": "Retrieve all payments made by a specific user.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2034,73 +3001,46 @@ const docTemplate = `{
                 "tags": [
                     "Debts"
                 ],
-                "summary": "List debtor records",
+                "summary": "List user payments",
                 "parameters": [
-                    {
-                        "type": "boolean",
-                        "description": "Filter by fully paid status",
-                        "name": "is_fully_pay",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by currency code",
-                        "name": "currency_code",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "Filter by description",
-                        "name": "description",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Filter by unpaid debts",
-                        "name": "no_paid_debts",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Maximum results",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number for pagination",
-                        "name": "page",
-                        "in": "query"
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of debtor records",
+                        "description": "User payment records",
                         "schema": {
-                            "$ref": "#/definitions/debts.DebtsList"
+                            "$ref": "#/definitions/debts.UserPaymentsRes"
                         }
                     },
                     "400": {
-                        "description": "Invalid filter value",
+                        "description": "Invalid user ID",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/debts/total-sum": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a new debtor record.",
+                "description": "Retrieve the total amount of debtor records for the company.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2110,48 +3050,37 @@ const docTemplate = `{
                 "tags": [
                     "Debts"
                 ],
-                "summary": "Create debtor",
-                "parameters": [
-                    {
-                        "description": "Debtor details",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.DebtsRequest"
-                        }
-                    }
-                ],
+                "summary": "Get total debtor sum",
                 "responses": {
-                    "201": {
-                        "description": "Created debtor record",
+                    "200": {
+                        "description": "Total debtor sum",
                         "schema": {
-                            "$ref": "#/definitions/debts.Debts"
+                            "$ref": "#/definitions/debts.SumMoney"
                         }
                     },
                     "400": {
-                        "description": "Invalid input",
+                        "description": "Bad request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/debts/client/{client_id}": {
+        "/debts/total-sum/{user_id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve debtor records for a specific client.",
+                "description": "Retrieve the total debtor amount for a specific user.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2161,70 +3090,76 @@ const docTemplate = `{
                 "tags": [
                     "Debts"
                 ],
-                "summary": "Get client debtor records",
+                "summary": "Get user's total debtor sum",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Client ID",
-                        "name": "client_id",
+                        "description": "User ID",
+                        "name": "user_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Debtor records for the client",
+                        "description": "User's total debtor sum",
                         "schema": {
-                            "$ref": "#/definitions/debts.DebtsList"
+                            "$ref": "#/definitions/debts.SumMoney"
                         }
                     },
                     "400": {
-                        "description": "Invalid client ID",
+                        "description": "Invalid user ID",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/debts/excel/{currency}": {
+        "/debts/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Export debtor records as an Excel file filtered by currency.",
+                "description": "Retrieve debtor details using its ID.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                    "application/json"
                 ],
                 "tags": [
                     "Debts"
                 ],
-                "summary": "Export debts to Excel",
+                "summary": "Get debtor by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Currency code",
-                        "name": "currency",
+                        "description": "Debtor ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Excel file with debtor records",
+                        "description": "Debtor details",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/debts.Debts"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
@@ -2236,14 +3171,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/debts/pay": {
-            "post": {
+        "/healthz": {
+            "get": {
+                "description": "Report that the gateway process is up. It does not check any dependency.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/company.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/permissions": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Make a payment toward a debtor record.",
+                "description": "Return the caller's claims, allowed branches and the routes they may call, grouped by resource",
                 "consumes": [
                     "application/json"
                 ],
@@ -2251,50 +3206,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Debts"
-                ],
-                "summary": "Process debtor payment",
-                "parameters": [
-                    {
-                        "description": "Payment details",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.PayDebtReq"
-                        }
-                    }
+                    "Me"
                 ],
+                "summary": "My permissions",
                 "responses": {
                     "200": {
-                        "description": "Updated debtor record",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/debts.Debts"
+                            "$ref": "#/definitions/handler.MePermissions"
                         }
                     },
-                    "400": {
-                        "description": "Invalid input",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
-                        "description": "Server error",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/debts/payment/{id}": {
+        "/policies": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve details for a specific payment.",
+                "description": "List the policy (p) and grouping (g) rules of the enforcer, optionally filtered by subject and domain (company ID or \"*\")",
                 "consumes": [
                     "application/json"
                 ],
@@ -2302,48 +3246,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Debts"
+                    "Policies"
                 ],
-                "summary": "Get payment details",
+                "summary": "List policy rules",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Payment ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Subject",
+                        "name": "subject",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Domain",
+                        "name": "domain",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Payment details",
-                        "schema": {
-                            "$ref": "#/definitions/debts.Payment"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid payment ID",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rbac.Rule"
+                            }
                         }
                     },
                     "500": {
-                        "description": "Server error",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
-            }
-        },
-        "/debts/payments": {
+            },
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve a list of payments",
+                "description": "Add a runtime rule: type \"p\" with [sub, dom, obj, act] or type \"g\" with [user, role, dom]",
                 "consumes": [
                     "application/json"
                 ],
@@ -2351,50 +3295,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Payments"
+                    "Policies"
                 ],
-                "summary": "Bought products for cash or debt",
+                "summary": "Add policy rule",
                 "parameters": [
                     {
-                        "description": "Sale data",
-                        "name": "Sale",
+                        "description": "Rule",
+                        "name": "Rule",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.PaymentSale"
+                            "$ref": "#/definitions/rbac.Rule"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/products.SaleResponse"
+                            "$ref": "#/definitions/rbac.Rule"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
-            }
-        },
-        "/debts/payments/{debt_id}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve all payments for a specific debtor record.",
+                "description": "Remove a runtime rule. Built-in rules from policy.csv cannot be removed here.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2402,48 +3350,56 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Debts"
+                    "Policies"
                 ],
-                "summary": "List payments by debtor ID",
+                "summary": "Remove policy rule",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Debtor ID",
-                        "name": "debt_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Rule",
+                        "name": "Rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rbac.Rule"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of payments",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/debts.PaymentList"
+                            "$ref": "#/definitions/rbac.Rule"
                         }
                     },
                     "400": {
-                        "description": "Invalid debtor ID",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
-                        "description": "Server error",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/debts/payments/{user_id}": {
+        "/policies/explain": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve all payments made by a specific user.",
+                "description": "Evaluate a request for a user the way the permission middleware does and show the rule that allowed it or why it was denied",
                 "consumes": [
                     "application/json"
                 ],
@@ -2451,48 +3407,74 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Debts"
+                    "Policies"
                 ],
-                "summary": "List user payments",
+                "summary": "Explain a policy decision",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "User ID",
                         "name": "user_id",
-                        "in": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Role from the user's token",
+                        "name": "role",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "company_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "HTTP method",
+                        "name": "method",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Request path",
+                        "name": "path",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User payment records",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/debts.UserPaymentsRes"
+                            "$ref": "#/definitions/rbac.Explanation"
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID",
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
-                        "description": "Server error",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/debts/total-sum": {
-            "get": {
+        "/policies/reload": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve the total amount of debtor records for the company.",
+                "description": "Reload the policy from policy.csv and the runtime rules file, and the ownership rules",
                 "consumes": [
                     "application/json"
                 ],
@@ -2500,39 +3482,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Debts"
+                    "Policies"
                 ],
-                "summary": "Get total debtor sum",
+                "summary": "Reload policies",
                 "responses": {
                     "200": {
-                        "description": "Total debtor sum",
-                        "schema": {
-                            "$ref": "#/definitions/debts.SumMoney"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad request",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/company.Message"
                         }
                     },
                     "500": {
-                        "description": "Server error",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
             }
         },
-        "/debts/total-sum/{user_id}": {
+        "/policies/versions": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve the total debtor amount for a specific user.",
+                "description": "List the saved versions of the runtime rules, oldest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -2540,48 +3516,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Debts"
-                ],
-                "summary": "Get user's total debtor sum",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Policies"
                 ],
+                "summary": "List policy versions",
                 "responses": {
                     "200": {
-                        "description": "User's total debtor sum",
-                        "schema": {
-                            "$ref": "#/definitions/debts.SumMoney"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid user ID",
-                        "schema": {
-                            "$ref": "#/definitions/products.Error"
-                        }
-                    },
-                    "500": {
-                        "description": "Server error",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rbac.Version"
+                            }
                         }
                     }
                 }
             }
         },
-        "/debts/{id}": {
-            "get": {
+        "/policies/versions/{version}/rollback": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve debtor details using its ID.",
+                "description": "Restore the runtime rules of a saved version. The rollback itself is saved as a new version.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2589,35 +3547,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Debts"
+                    "Policies"
                 ],
-                "summary": "Get debtor by ID",
+                "summary": "Roll back policies",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Debtor ID",
-                        "name": "id",
+                        "type": "integer",
+                        "description": "Version ID",
+                        "name": "version",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Debtor details",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/debts.Debts"
+                            "$ref": "#/definitions/company.Message"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
-                        "description": "Server error",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -2699,13 +3663,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -2793,13 +3757,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid input or bad request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -2858,19 +3822,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid input or bad request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -2937,13 +3895,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad request due to invalid query parameters",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -3053,13 +4011,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid input or bad request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -3120,13 +4078,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid input or bad request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -3174,13 +4132,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid input or bad request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -3230,13 +4188,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid request parameters",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -3356,13 +4314,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -3456,13 +4414,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -3510,13 +4468,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -3599,13 +4557,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -3655,13 +4613,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -3711,13 +4669,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -3774,13 +4732,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -3828,13 +4786,39 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Probe the user, product, company and debt services, MinIO and Kafka and report the status and latency of each. Returns 503 when a critical dependency (READINESS_CRITICAL) is down.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
                         }
                     }
                 }
@@ -4158,13 +5142,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4214,13 +5198,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4265,13 +5249,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4321,13 +5305,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4338,7 +5322,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update the details of an existing sale by ID",
+                "description": "Update the details of an existing sale by ID. Workers may only update their own sales within SALE_EDIT_WINDOW minutes of creating them.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4384,13 +5368,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4438,13 +5428,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4489,10 +5479,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/products.BranchIncomeRes"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4549,13 +5545,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4612,13 +5608,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4675,13 +5671,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4731,7 +5727,7 @@ const docTemplate = `{
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4788,13 +5784,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4851,13 +5847,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4914,13 +5910,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -4977,13 +5973,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -5044,13 +6040,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Branch ID is required in the header",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -5107,13 +6103,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -5170,13 +6166,13 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/products.Error"
+                            "$ref": "#/definitions/entity.Error"
                         }
                     }
                 }
@@ -5377,7 +6373,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entity.Client"
+                            "$ref": "#/definitions/entity.ClientUpdate"
                         }
                     }
                 ],
@@ -5709,7 +6705,7 @@ const docTemplate = `{
         },
         "/user/get/access-token": {
             "post": {
-                "description": "Get Access token with refresh token",
+                "description": "Get Access token with refresh token. The refresh token cookie is rotated on every call;\npresenting an already used refresh token revokes every session of that login, unless it is\npresented again within REFRESH_REUSE_GRACE seconds of its first use (e.g. by a second tab).",
                 "consumes": [
                     "application/json"
                 ],
@@ -5727,8 +6723,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.Token"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/entity.Error"
                         }
@@ -5887,6 +6883,76 @@ const docTemplate = `{
                 }
             }
         },
+        "/user/logout": {
+            "post": {
+                "description": "Revoke the refresh token of the current session and clear its cookie",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/logout/all": {
+            "post": {
+                "description": "Revoke every refresh and access token issued to the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Logout from all devices",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Error"
+                        }
+                    }
+                }
+            }
+        },
         "/user/register": {
             "post": {
                 "description": "Register a new user account",
@@ -5988,6 +7054,62 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "audit.Event": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "company_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "DELETE"
+                },
+                "outcome": {
+                    "type": "string",
+                    "enum": [
+                        "success",
+                        "failure"
+                    ]
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/sales/5f1c7c1e-7d0e-4c59-9a53-0b7f7d0e4c59"
+                },
+                "payload": {
+                    "description": "Payload is the request body or form, with sensitive fields masked.",
+                    "type": "object"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "route": {
+                    "type": "string",
+                    "example": "/sales/:id"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
         "company.BranchResponse": {
             "type": "object",
             "properties": {
@@ -6199,23 +7321,6 @@ const docTemplate = `{
                 }
             }
         },
-        "debts.PayDebtsReq": {
-            "type": "object",
-            "properties": {
-                "company_id": {
-                    "type": "string"
-                },
-                "debt_id": {
-                    "type": "string"
-                },
-                "paid_amount": {
-                    "type": "number"
-                },
-                "pay_type": {
-                    "type": "string"
-                }
-            }
-        },
         "debts.Payment": {
             "type": "object",
             "properties": {
@@ -6297,18 +7402,32 @@ const docTemplate = `{
         },
         "entity.AdjustmentRequest": {
             "type": "object",
+            "required": [
+                "adjustment_type",
+                "currency_code",
+                "user_id"
+            ],
             "properties": {
                 "adjustment_date": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "2024-01-31"
                 },
                 "adjustment_type": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "bonus",
+                        "penalty"
+                    ]
                 },
                 "amount": {
                     "type": "number"
                 },
                 "currency_code": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "uzs",
+                        "usd"
+                    ]
                 },
                 "user_id": {
                     "type": "string"
@@ -6319,37 +7438,71 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "adjustment_date": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "2024-01-31"
                 },
                 "adjustment_type": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "bonus",
+                        "penalty"
+                    ]
                 },
                 "amount": {
-                    "type": "number"
+                    "type": "number",
+                    "minimum": 0
                 },
                 "currency_code": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "uzs",
+                        "usd"
+                    ]
                 }
             }
         },
-        "entity.Client": {
+        "entity.CashFlowRequest": {
             "type": "object",
+            "required": [
+                "payment_method"
+            ],
             "properties": {
-                "address": {
+                "amount": {
+                    "type": "number"
+                },
+                "description": {
                     "type": "string"
                 },
-                "client_type": {
+                "payment_method": {
+                    "type": "string",
+                    "enum": [
+                        "uzs",
+                        "usd",
+                        "card"
+                    ]
+                }
+            }
+        },
+        "entity.ClientRequest": {
+            "type": "object",
+            "required": [
+                "full_name",
+                "phone"
+            ],
+            "properties": {
+                "address": {
                     "type": "string"
                 },
                 "full_name": {
                     "type": "string"
                 },
                 "phone": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "+998901234567"
                 }
             }
         },
-        "entity.ClientRequest": {
+        "entity.ClientUpdate": {
             "type": "object",
             "properties": {
                 "address": {
@@ -6359,15 +7512,20 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "phone": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "+998901234567"
                 }
             }
         },
         "entity.CreateBulkProductsRequest": {
             "type": "object",
+            "required": [
+                "products"
+            ],
             "properties": {
                 "products": {
                     "type": "array",
+                    "minItems": 1,
                     "items": {
                         "$ref": "#/definitions/entity.CreateProductRequestBulk"
                     }
@@ -6390,21 +7548,27 @@ const docTemplate = `{
         },
         "entity.CreateProductRequestBulk": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
                 "bill_format": {
                     "type": "string"
                 },
                 "incoming_price": {
-                    "type": "number"
+                    "type": "number",
+                    "minimum": 0
                 },
                 "name": {
                     "type": "string"
                 },
                 "standard_price": {
-                    "type": "number"
+                    "type": "number",
+                    "minimum": 0
                 },
                 "total_count": {
-                    "type": "integer"
+                    "type": "integer",
+                    "minimum": 0
                 }
             }
         },
@@ -6437,15 +7601,24 @@ const docTemplate = `{
         },
         "entity.DebtsRequest": {
             "type": "object",
+            "required": [
+                "client_id",
+                "currency_code"
+            ],
             "properties": {
                 "client_id": {
                     "type": "string"
                 },
                 "currency_code": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "uzs",
+                        "usd"
+                    ]
                 },
                 "should_pay_at": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "2024-01-31"
                 },
                 "total_amount": {
                     "type": "number"
@@ -6455,13 +7628,28 @@ const docTemplate = `{
         "entity.Error": {
             "type": "object",
             "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "NOT_FOUND"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {}
+                },
                 "message": {
+                    "type": "string",
+                    "example": "resource not found"
+                },
+                "request_id": {
                     "type": "string"
                 }
             }
         },
         "entity.PayDebtReq": {
             "type": "object",
+            "required": [
+                "debt_id"
+            ],
             "properties": {
                 "debt_id": {
                     "type": "string"
@@ -6473,6 +7661,11 @@ const docTemplate = `{
         },
         "entity.PaymentSale": {
             "type": "object",
+            "required": [
+                "client_id",
+                "payment_method",
+                "sold_products"
+            ],
             "properties": {
                 "branch_id": {
                     "type": "string"
@@ -6481,39 +7674,60 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "currency_code": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "uzs",
+                        "usd"
+                    ]
                 },
                 "is_fully_debt": {
                     "type": "boolean"
                 },
                 "paid_amount": {
-                    "type": "number"
+                    "type": "number",
+                    "minimum": 0
                 },
                 "payment_method": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "cash",
+                        "debt"
+                    ]
                 },
                 "sold_products": {
                     "type": "array",
+                    "minItems": 1,
                     "items": {
-                        "$ref": "#/definitions/products.SalesItem"
+                        "$ref": "#/definitions/entity.SalesItem"
                     }
                 }
             }
         },
         "entity.Purchase": {
             "type": "object",
+            "required": [
+                "items",
+                "payment_method",
+                "supplier_id"
+            ],
             "properties": {
                 "description": {
                     "type": "string"
                 },
                 "items": {
                     "type": "array",
+                    "minItems": 1,
                     "items": {
                         "$ref": "#/definitions/entity.PurchaseItem"
                     }
                 },
                 "payment_method": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "uzs",
+                        "usd",
+                        "card"
+                    ]
                 },
                 "supplier_id": {
                     "type": "string"
@@ -6522,6 +7736,9 @@ const docTemplate = `{
         },
         "entity.PurchaseItem": {
             "type": "object",
+            "required": [
+                "product_id"
+            ],
             "properties": {
                 "product_id": {
                     "type": "string"
@@ -6540,11 +7757,13 @@ const docTemplate = `{
                 "description": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "string"
-                },
                 "payment_method": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "uzs",
+                        "usd",
+                        "card"
+                    ]
                 },
                 "supplier_id": {
                     "type": "string"
@@ -6553,15 +7772,24 @@ const docTemplate = `{
         },
         "entity.SalaryRequest": {
             "type": "object",
+            "required": [
+                "currency_code",
+                "user_id"
+            ],
             "properties": {
                 "amount": {
                     "type": "number"
                 },
                 "currency_code": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "uzs",
+                        "usd"
+                    ]
                 },
                 "salary_date": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "2024-01-31"
                 },
                 "user_id": {
                     "type": "string"
@@ -6572,18 +7800,27 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "amount": {
-                    "type": "number"
+                    "type": "number",
+                    "minimum": 0
                 },
                 "currency_code": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "uzs",
+                        "usd"
+                    ]
                 },
                 "salary_date": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "2024-01-31"
                 }
             }
         },
         "entity.Sale": {
             "type": "object",
+            "required": [
+                "sold_products"
+            ],
             "properties": {
                 "client_id": {
                     "type": "string"
@@ -6599,13 +7836,20 @@ const docTemplate = `{
                     "default": false
                 },
                 "paid_amount": {
-                    "type": "number"
+                    "type": "number",
+                    "minimum": 0
                 },
                 "payment_method": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "uzs",
+                        "usd",
+                        "card"
+                    ]
                 },
                 "sold_products": {
                     "type": "array",
+                    "minItems": 1,
                     "items": {
                         "$ref": "#/definitions/entity.SalesItem"
                     }
@@ -6619,44 +7863,46 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "payment_method": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "uzs",
+                        "usd",
+                        "card"
+                    ]
                 }
             }
         },
         "entity.SalesItem": {
             "type": "object",
+            "required": [
+                "product_id"
+            ],
             "properties": {
-                "id": {
-                    "type": "string"
-                },
                 "product_id": {
                     "type": "string"
                 },
                 "quantity": {
                     "type": "integer"
                 },
-                "sale_id": {
-                    "type": "string"
-                },
                 "sale_price": {
-                    "type": "number"
-                },
-                "total_price": {
-                    "type": "number"
+                    "type": "number",
+                    "minimum": 0
                 }
             }
         },
         "entity.TransferReq": {
             "type": "object",
+            "required": [
+                "products",
+                "to_branch_id"
+            ],
             "properties": {
-                "company_id": {
-                    "type": "string"
-                },
                 "description": {
                     "type": "string"
                 },
                 "products": {
                     "type": "array",
+                    "minItems": 1,
                     "items": {
                         "$ref": "#/definitions/entity.TransfersProductsReq"
                     }
@@ -6668,6 +7914,9 @@ const docTemplate = `{
         },
         "entity.TransfersProductsReq": {
             "type": "object",
+            "required": [
+                "product_id"
+            ],
             "properties": {
                 "product_id": {
                     "type": "string"
@@ -6691,6 +7940,59 @@ const docTemplate = `{
                 }
             }
         },
+        "entity.UserBranches": {
+            "type": "object",
+            "properties": {
+                "branch_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "restricted": {
+                    "type": "boolean"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.UserBranchesReq": {
+            "type": "object",
+            "properties": {
+                "branch_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "entity.UserRoles": {
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.UserRolesReq": {
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "entity.UserUpdateRequest": {
             "type": "object",
             "properties": {
@@ -6717,6 +8019,44 @@ const docTemplate = `{
                 }
             }
         },
+        "handler.MePermissions": {
+            "type": "object",
+            "properties": {
+                "branches": {
+                    "description": "Branches are the branches the user may address with the branch_id\nheader. It is empty for admins, who may address any branch.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "company_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "description": "Permissions maps a resource to the routes the user may call on it.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/rbac.Permission"
+                        }
+                    }
+                },
+                "role": {
+                    "type": "string"
+                },
+                "roles": {
+                    "description": "Roles are the company roles of the user; when set they replace Role.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "handler.Token": {
             "type": "object",
             "properties": {
@@ -6725,6 +8065,37 @@ const docTemplate = `{
                 }
             }
         },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.Result"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "health.Result": {
+            "type": "object",
+            "properties": {
+                "critical": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
         "products.BranchIncomeData": {
             "type": "object",
             "properties": {
@@ -6807,30 +8178,6 @@ const docTemplate = `{
                 }
             }
         },
-        "products.CashFlowRequest": {
-            "type": "object",
-            "properties": {
-                "amount": {
-                    "type": "number"
-                },
-                "branch_id": {
-                    "description": "Added branch_id",
-                    "type": "string"
-                },
-                "company_id": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "payment_method": {
-                    "type": "string"
-                },
-                "user_id": {
-                    "type": "string"
-                }
-            }
-        },
         "products.Category": {
             "type": "object",
             "properties": {
@@ -6887,14 +8234,6 @@ const docTemplate = `{
                 }
             }
         },
-        "products.Error": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
-                }
-            }
-        },
         "products.GetClientDashboardResponse": {
             "type": "object",
             "properties": {
@@ -7352,6 +8691,151 @@ const docTemplate = `{
                 }
             }
         },
+        "rbac.Explanation": {
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "boolean"
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "matched": {
+                    "description": "Matched is the rule that allowed the request.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/rbac.Rule"
+                        }
+                    ]
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "roles": {
+                    "description": "Roles are the roles the subject holds in the domain, directly or\nthrough inheritance.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "route": {
+                    "description": "Route is the route template serving the request, if any.",
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "rbac.Permission": {
+            "type": "object",
+            "properties": {
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "rbac.Role": {
+            "type": "object",
+            "properties": {
+                "inherits": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rbac.Permission"
+                    }
+                }
+            }
+        },
+        "rbac.Rule": {
+            "type": "object",
+            "required": [
+                "rule",
+                "type"
+            ],
+            "properties": {
+                "builtin": {
+                    "type": "boolean"
+                },
+                "rule": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "rbac.Version": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "rules": {
+                    "type": "integer"
+                }
+            }
+        },
+        "token.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "token.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/token.JWK"
+                    }
+                }
+            }
+        },
         "user.Adjustment": {
             "type": "object",
             "properties": {
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"gateway/internal/api/response"
+	"gateway/internal/audit"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxAuditLimit bounds the number of events one GET /audit returns.
+const maxAuditLimit = 500
+
+// ListAudit godoc
+// @Summary List audit events
+// @Description Recent POST, PUT and DELETE calls made in the caller's company, newest first, with who made them, on which record and how they ended
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param actor_id query string false "ID of the user who made the call"
+// @Param method query string false "HTTP method" Enums(POST, PUT, PATCH, DELETE)
+// @Param route query string false "Route template, e.g. /sales/:id"
+// @Param resource_id query string false "ID of the record the call addressed"
+// @Param from query string false "Earliest time, RFC 3339"
+// @Param to query string false "Latest time (exclusive), RFC 3339"
+// @Param limit query int false "Number of events to return (default 50, at most 500)"
+// @Success 200 {array} audit.Event
+// @Failure 400 {object} entity.Error
+// @Router /audit [get]
+func (h *Handler) ListAudit(c *gin.Context) {
+	filter := audit.Filter{
+		ActorID:    c.Query("actor_id"),
+		Method:     c.Query("method"),
+		Route:      c.Query("route"),
+		ResourceID: c.Query("resource_id"),
+		Limit:      50,
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > maxAuditLimit {
+			response.Error(c, http.StatusBadRequest, "limit must be a number from 1 to 500")
+			return
+		}
+		filter.Limit = n
+	}
+
+	for _, bound := range []struct {
+		param string
+		value *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		if s := c.Query(bound.param); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				response.Error(c, http.StatusBadRequest, bound.param+" must be an RFC 3339 time")
+				return
+			}
+			*bound.value = t
+		}
+	}
+
+	c.JSON(http.StatusOK, h.Audit.Recent(c.GetString("company_id"), filter))
+}
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"fmt"
+	"gateway/internal/api/response"
+	"gateway/internal/entity"
 	"gateway/internal/generated/company"
 	"net/http"
 	"strconv"
@@ -17,7 +19,7 @@ import (
 // @Security ApiKeyAuth
 // @Param input body company.CreateBranchRequest true "Branch details"
 // @Success 200 {object} company.BranchResponse
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} entity.Error
 // @Router /branches/create [post]
 func (h *Handler) CreateBranch(c *gin.Context) {
 	var req company.CreateBranchRequest
@@ -25,23 +27,25 @@ func (h *Handler) CreateBranch(c *gin.Context) {
 	// Получение company_id из контекста
 	companyID, exists := c.Get("company_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "company_id not found"})
+		response.Error(c, http.StatusUnauthorized, "company_id not found")
 		return
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
 	req.CompanyId = companyID.(string)
 	res, err := h.CompanyClient.CreateBranch(c, &req)
 	if err != nil {
-		h.log.Error(fmt.Sprintf("CreateBranch request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("CreateBranch request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 
+	h.Branches.Invalidate(req.CompanyId)
+
 	c.JSON(http.StatusOK, res)
 }
 
@@ -53,7 +57,7 @@ func (h *Handler) CreateBranch(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Param branch_id path string true "Branch ID"
 // @Success 200 {object} company.BranchResponse
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} entity.Error
 // @Router /branches/{branch_id} [get]
 func (h *Handler) GetBranch(c *gin.Context) {
 	req := &company.GetBranchRequest{
@@ -62,15 +66,15 @@ func (h *Handler) GetBranch(c *gin.Context) {
 
 	companyID, exists := c.Get("company_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "company_id not found"})
+		response.Error(c, http.StatusUnauthorized, "company_id not found")
 		return
 	}
 
 	req.CompanyId = companyID.(string)
 	res, err := h.CompanyClient.GetBranch(c, req)
 	if err != nil {
-		h.log.Error(fmt.Sprintf("GetBranch request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("GetBranch request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -86,18 +90,18 @@ func (h *Handler) GetBranch(c *gin.Context) {
 // @Param branch_id path string true "Branch ID"
 // @Param input body company.UpdateBranchRequest true "Updated branch details"
 // @Success 200 {object} company.BranchResponse
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} entity.Error
 // @Router /branches/{branch_id} [put]
 func (h *Handler) UpdateBranch(c *gin.Context) {
 	var req company.UpdateBranchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
 	companyID, exists := c.Get("company_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "company_id not found"})
+		response.Error(c, http.StatusUnauthorized, "company_id not found")
 		return
 	}
 
@@ -105,8 +109,8 @@ func (h *Handler) UpdateBranch(c *gin.Context) {
 	req.CompanyId = companyID.(string)
 	res, err := h.CompanyClient.UpdateBranch(c, &req)
 	if err != nil {
-		h.log.Error(fmt.Sprintf("UpdateBranch request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("UpdateBranch request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -121,18 +125,18 @@ func (h *Handler) UpdateBranch(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Param branch_id path string true "Branch ID"
 // @Success 200 {object} company.Message
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} entity.Error
 // @Router /branches/{branch_id} [delete]
 func (h *Handler) DeleteBranch(c *gin.Context) {
 	branchID := c.Param("branch_id")
 	if branchID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "branch_id is required"})
+		response.Error(c, http.StatusBadRequest, "branch_id is required")
 		return
 	}
 
 	companyID, exists := c.Get("company_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "company_id not found"})
+		response.Error(c, http.StatusUnauthorized, "company_id not found")
 		return
 	}
 
@@ -143,11 +147,13 @@ func (h *Handler) DeleteBranch(c *gin.Context) {
 
 	res, err := h.CompanyClient.DeleteBranch(c, req)
 	if err != nil {
-		h.log.Error(fmt.Sprintf("DeleteBranch request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("DeleteBranch request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 
+	h.Branches.Invalidate(req.CompanyId)
+
 	c.JSON(http.StatusOK, res)
 }
 
@@ -160,12 +166,12 @@ func (h *Handler) DeleteBranch(c *gin.Context) {
 // @Param limit query int false "Limit"
 // @Param page query int false "Page"
 // @Success 200 {object} company.ListBranchesResponse
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} entity.Error
 // @Router /branches/list [get]
 func (h *Handler) ListBranches(c *gin.Context) {
 	companyID, exists := c.Get("company_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "company_id not found"})
+		response.Error(c, http.StatusUnauthorized, "company_id not found")
 		return
 	}
 
@@ -180,14 +186,107 @@ func (h *Handler) ListBranches(c *gin.Context) {
 
 	res, err := h.CompanyClient.ListBranches(c, req)
 	if err != nil {
-		h.log.Error(fmt.Sprintf("ListBranches request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("ListBranches request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, res)
 }
 
+// @Summary Get User Branches
+// @Description Get the branches a user of the company may work in. Users without an assignment may work in no branch, unless BRANCH_ALLOW_UNASSIGNED is set.
+// @Tags Branches
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id path string true "User ID"
+// @Success 200 {object} entity.UserBranches
+// @Failure 401 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /companies/users/{user_id}/branches [get]
+func (h *Handler) GetUserBranches(c *gin.Context) {
+	companyID, exists := c.Get("company_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "company_id not found")
+		return
+	}
+
+	userID := c.Param("user_id")
+	_, assigned, err := h.Branches.Assignments().Get(c.Request.Context(), companyID.(string), userID)
+	if err != nil {
+		h.log.ErrorContext(c, "Error reading branch assignment", "error", err.Error())
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	branchIDs, err := h.Branches.Allowed(c.Request.Context(), companyID.(string), userID, "")
+	if err != nil {
+		h.log.ErrorContext(c, fmt.Sprintf("GetUserBranches request error: %v", err))
+		response.FromGRPC(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.UserBranches{UserId: userID, BranchIds: branchIDs, Restricted: assigned || !h.Branches.AllowsUnassigned()})
+}
+
+// @Summary Set User Branches
+// @Description Restrict a user of the company to the given branches. An empty list removes the assignment, which leaves the user no branch unless BRANCH_ALLOW_UNASSIGNED is set.
+// @Tags Branches
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id path string true "User ID"
+// @Param input body entity.UserBranchesReq true "Branch IDs"
+// @Success 200 {object} entity.UserBranches
+// @Failure 400 {object} entity.Error
+// @Failure 401 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /companies/users/{user_id}/branches [put]
+func (h *Handler) SetUserBranches(c *gin.Context) {
+	var req entity.UserBranchesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Invalid(c, err)
+		return
+	}
+
+	companyID, exists := c.Get("company_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "company_id not found")
+		return
+	}
+
+	for _, branchID := range req.BranchIds {
+		inCompany, err := h.Branches.InCompany(c.Request.Context(), companyID.(string), branchID)
+		if err != nil {
+			h.log.ErrorContext(c, fmt.Sprintf("SetUserBranches request error: %v", err))
+			response.FromGRPC(c, err)
+			return
+		}
+		if !inCompany {
+			response.Error(c, http.StatusBadRequest, fmt.Sprintf("branch %s does not belong to the company", branchID))
+			return
+		}
+	}
+
+	userID := c.Param("user_id")
+	store := h.Branches.Assignments()
+
+	var err error
+	if len(req.BranchIds) == 0 {
+		err = store.Delete(c.Request.Context(), companyID.(string), userID)
+	} else {
+		err = store.Set(c.Request.Context(), companyID.(string), userID, req.BranchIds)
+	}
+	if err != nil {
+		h.log.ErrorContext(c, "Error saving branch assignment", "error", err.Error())
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.UserBranches{UserId: userID, BranchIds: req.BranchIds, Restricted: len(req.BranchIds) > 0 || !h.Branches.AllowsUnassigned()})
+}
+
 // stringToInt is a helper function to convert strings to integers
 func stringToInt(s string) int32 {
 	value, err := strconv.Atoi(s)
@@ -1,8 +1,12 @@
 package handler
 
 import (
+	"gateway/internal/api/response"
+	"gateway/internal/entity"
 	"gateway/internal/generated/user"
+	"gateway/internal/rbac"
 	"github.com/gin-gonic/gin"
+	"math"
 	"net/http"
 	"strconv"
 )
@@ -20,22 +24,22 @@ import (
 // @Failure 500 {object} entity.Error
 // @Router /clients [post]
 func (h *Handler) CreateClient(c *gin.Context) {
-	var req user.ClientRequest
+	var body entity.ClientRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Error parsing CreateClient request body", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Invalid(c, err)
 		return
 	}
 
+	req := body.Proto()
 	req.CompanyId = c.MustGet("company_id").(string)
 	req.ClientType = "client" // Если для клиента всегда "client", оставляем так.
 	req.Type = "client"
 
-	res, err := h.UserClient.CreateClient(c, &req)
+	res, err := h.UserClient.CreateClient(c, req)
 	if err != nil {
-		h.log.Error("Error creating client", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error creating client", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -61,8 +65,8 @@ func (h *Handler) GetClient(c *gin.Context) {
 
 	res, err := h.UserClient.GetClient(c, req)
 	if err != nil {
-		h.log.Error("Error fetching client", "client_id", id, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching client", "client_id", id, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -109,8 +113,8 @@ func (h *Handler) GetClientList(c *gin.Context) {
 
 	res, err := h.UserClient.GetListClient(c, &filter)
 	if err != nil {
-		h.log.Error("Error retrieving client list", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error retrieving client list", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -125,38 +129,55 @@ func (h *Handler) GetClientList(c *gin.Context) {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path string true "Client ID"
-// @Param Client body entity.Client true "Updated client data"
+// @Param Client body entity.ClientUpdate true "Updated client data"
 // @Success 200 {object} user.ClientResponse
 // @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
 // @Failure 500 {object} entity.Error
 // @Router /clients/{id} [put]
 func (h *Handler) UpdateClient(c *gin.Context) {
 	id := c.Param("id")
-	var req user.ClientUpdateRequest
+	var body entity.ClientUpdate
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Error parsing UpdateClient request body", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Invalid(c, err)
 		return
 	}
 
-	companyID := c.MustGet("company_id").(string)
-	res, err := h.UserClient.UpdateClient(c, &user.ClientUpdateRequest{
-		Id:        id,
-		FullName:  req.FullName,
-		Address:   req.Address,
-		Phone:     req.Phone,
-		CompanyId: companyID,
-	})
+	req := body.Proto()
+	req.Id = id
+	req.CompanyId = c.MustGet("company_id").(string)
+
+	res, err := h.UserClient.UpdateClient(c, req)
 	if err != nil {
-		h.log.Error("Error updating client", "client_id", id, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error updating client", "client_id", id, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, res)
 }
 
+// ClientRecord loads the client addressed by the request for the ownership
+// rules. Clients do not record who created them, so Owner is left empty.
+func (h *Handler) ClientRecord(c *gin.Context) (rbac.Record, error) {
+	client, err := h.UserClient.GetClient(c, &user.UserIDRequest{
+		Id:        c.Param("id"),
+		CompanyId: c.MustGet("company_id").(string),
+	})
+	if err != nil {
+		h.log.ErrorContext(c, "Error fetching client for ownership check", "error", err.Error())
+		return rbac.Record{}, err
+	}
+
+	return rbac.Record{
+		Type:       "client",
+		Id:         client.Id,
+		CompanyId:  client.CompanyId,
+		AgeMinutes: math.Inf(1),
+	}, nil
+}
+
 // DeleteClient godoc
 // @Summary Delete a client
 // @Description Delete a client by ID
@@ -176,8 +197,8 @@ func (h *Handler) DeleteClient(c *gin.Context) {
 
 	res, err := h.UserClient.DeleteClient(c, req)
 	if err != nil {
-		h.log.Error("Error deleting client", "client_id", id, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error deleting client", "client_id", id, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -199,22 +220,22 @@ func (h *Handler) DeleteClient(c *gin.Context) {
 // @Failure 500 {object} entity.Error
 // @Router /supplier [post]
 func (h *Handler) CreateSupplier(c *gin.Context) {
-	var req user.ClientRequest
+	var body entity.ClientRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Error parsing CreateSupplier request body", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Invalid(c, err)
 		return
 	}
 
+	req := body.Proto()
 	req.CompanyId = c.MustGet("company_id").(string)
 	req.Type = "supplier"
 	req.ClientType = "client"
 
-	res, err := h.UserClient.CreateClient(c, &req)
+	res, err := h.UserClient.CreateClient(c, req)
 	if err != nil {
-		h.log.Error("Error creating suppeyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJpZCI6IjJiZGIzNTZiLTU2MWYtNDMwNC1iZjBjLTYwZDZlOWQ2YjEwOCIsImZpcnN0X25hbWUiOiJJYnJvaGltIiwicGhvbmVfbnVtYmVyIjoiKzk5ODk0NjkxMTExMSIsImNvbXBhbnlfaWQiOiI2YTVjZjYwZC1mMzZjLTQyNmQtODBkNy0xNWRlOGIzOWUwOTciLCJyb2xlIjoib3duZXIiLCJleHAiOjE3NDQxNDgwNTUsImlhdCI6MTc0NDEwNDg1NX0._kAyg2nsG4gc0jUasgQSKvBtRNThdkFP6CFULEbSZi8lier", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error creating supplier", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -240,8 +261,8 @@ func (h *Handler) GetSupplier(c *gin.Context) {
 
 	res, err := h.UserClient.GetClient(c, req)
 	if err != nil {
-		h.log.Error("Error fetching supplier", "supplier_id", id, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching supplier", "supplier_id", id, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -286,8 +307,8 @@ func (h *Handler) GetSupplierList(c *gin.Context) {
 
 	res, err := h.UserClient.GetListClient(c, &filter)
 	if err != nil {
-		h.log.Error("Error retrieving supplier list", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error retrieving supplier list", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -302,32 +323,28 @@ func (h *Handler) GetSupplierList(c *gin.Context) {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path string true "Supplier ID"
-// @Param Supplier body entity.Client true "Updated supplier data"
+// @Param Supplier body entity.ClientUpdate true "Updated supplier data"
 // @Success 200 {object} user.ClientResponse
 // @Failure 400 {object} entity.Error
 // @Failure 500 {object} entity.Error
 // @Router /supplier/{id} [put]
 func (h *Handler) UpdateSupplier(c *gin.Context) {
 	id := c.Param("id")
-	var req user.ClientUpdateRequest
+	var body entity.ClientUpdate
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Error parsing UpdateSupplier request body", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Invalid(c, err)
 		return
 	}
 
-	companyID := c.MustGet("company_id").(string)
-	res, err := h.UserClient.UpdateClient(c, &user.ClientUpdateRequest{
-		Id:        id,
-		FullName:  req.FullName,
-		Address:   req.Address,
-		Phone:     req.Phone,
-		CompanyId: companyID,
-	})
+	req := body.Proto()
+	req.Id = id
+	req.CompanyId = c.MustGet("company_id").(string)
+
+	res, err := h.UserClient.UpdateClient(c, req)
 	if err != nil {
-		h.log.Error("Error updating supplier", "supplier_id", id, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error updating supplier", "supplier_id", id, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -353,8 +370,8 @@ func (h *Handler) DeleteSupplier(c *gin.Context) {
 
 	res, err := h.UserClient.DeleteClient(c, req)
 	if err != nil {
-		h.log.Error("Error deleting supplier", "supplier_id", id, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error deleting supplier", "supplier_id", id, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"fmt"
+	"gateway/internal/api/response"
 	"gateway/internal/entity"
 	"gateway/internal/generated/company"
 	"net/http"
@@ -19,17 +20,17 @@ import (
 // @Security ApiKeyAuth
 // @Param input body entity.CreateCompanyRequest true "Company details"
 // @Success 200 {object} company.CompanyResponse
-// @Failure 400 {object} string
+// @Failure 400 {object} entity.Error
 // @Router /companies/admin [post]
 func (h *Handler) CreateCompanyA(c *gin.Context) {
 
 	var req entity.CreateCompanyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
-	h.log.Debug(fmt.Sprintf("[%s] CreateCompany name: %s",
+	h.log.DebugContext(c, fmt.Sprintf("[%s] CreateCompany name: %s",
 		time.Now().Format("02-01-2006 15:04:05"), req.Name))
 
 	res, err := h.CompanyClient.CreateCompany(c, &company.CreateCompanyRequest{
@@ -38,8 +39,8 @@ func (h *Handler) CreateCompanyA(c *gin.Context) {
 		UserId:  c.MustGet("id").(string),
 	})
 	if err != nil {
-		h.log.Error(fmt.Sprintf("CreateCompany request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("CreateCompany request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -52,14 +53,14 @@ func (h *Handler) CreateCompanyA(c *gin.Context) {
 // @Produce json
 // @Security ApiKeyAuth
 // @Success 200 {object} company.CompanyResponse
-// @Failure 400 {object} string
+// @Failure 400 {object} entity.Error
 // @Router /companies [get]
 func (h *Handler) GetCompany(c *gin.Context) {
 	req := &company.GetCompanyRequest{CompanyId: c.MustGet("company_id").(string)}
 	res, err := h.CompanyClient.GetCompany(c, req)
 	if err != nil {
-		h.log.Error(fmt.Sprintf("GetCompany request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("GetCompany request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -73,14 +74,14 @@ func (h *Handler) GetCompany(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Param company_id path string true "Company ID"
 // @Success 200 {object} company.CompanyResponse
-// @Failure 400 {object} string
+// @Failure 400 {object} entity.Error
 // @Router /companies/admin/{company_id} [get]
 func (h *Handler) GetCompanyA(c *gin.Context) {
 	req := &company.GetCompanyRequest{CompanyId: c.Param("company_id")}
 	res, err := h.CompanyClient.GetCompany(c, req)
 	if err != nil {
-		h.log.Error(fmt.Sprintf("GetCompany request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("GetCompany request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -94,12 +95,12 @@ func (h *Handler) GetCompanyA(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Param input body entity.CreateCompanyRequest true "Updated company details"
 // @Success 200 {object} company.CompanyResponse
-// @Failure 400 {object} string
+// @Failure 400 {object} entity.Error
 // @Router /companies [put]
 func (h *Handler) UpdateCompany(c *gin.Context) {
 	var req entity.CreateCompanyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
@@ -110,8 +111,8 @@ func (h *Handler) UpdateCompany(c *gin.Context) {
 		Logo:      req.Logo,
 	})
 	if err != nil {
-		h.log.Error(fmt.Sprintf("UpdateCompany request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("UpdateCompany request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -126,20 +127,20 @@ func (h *Handler) UpdateCompany(c *gin.Context) {
 // @Param company_id path string true "Company ID"
 // @Param input body entity.UpdateCompanyRequest true "Updated company details"
 // @Success 200 {object} company.CompanyResponse
-// @Failure 400 {object} string
+// @Failure 400 {object} entity.Error
 // @Router /companies/admin/{company_id} [put]
 func (h *Handler) UpdateCompanyA(c *gin.Context) {
 	var req company.UpdateCompanyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 	req.CompanyId = c.Param("company_id")
 
 	res, err := h.CompanyClient.UpdateCompany(c, &req)
 	if err != nil {
-		h.log.Error(fmt.Sprintf("UpdateCompany request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("UpdateCompany request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -163,7 +164,7 @@ func (h *Handler) UpdateCompanyA(c *gin.Context) {
 //	req := &company.DeleteCompanyRequest{CompanyId: companyID}
 //	res, err := h.CompanyClient.DeleteCompany(c, req)
 //	if err != nil {
-//		h.log.Error(fmt.Sprintf("DeleteCompany request error: %v", err))
+//		h.log.ErrorContext(c, fmt.Sprintf("DeleteCompany request error: %v", err))
 //		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 //		return
 //	}
@@ -183,14 +184,14 @@ func (h *Handler) UpdateCompanyA(c *gin.Context) {
 func (h *Handler) DeleteCompanyA(c *gin.Context) {
 	companyID := c.Param("company_id")
 	if companyID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id is required"})
+		response.Error(c, http.StatusBadRequest, "company_id is required")
 		return
 	}
 	req := &company.DeleteCompanyRequest{CompanyId: companyID}
 	res, err := h.CompanyClient.DeleteCompany(c, req)
 	if err != nil {
-		h.log.Error(fmt.Sprintf("DeleteCompany request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("DeleteCompany request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -205,7 +206,7 @@ func (h *Handler) DeleteCompanyA(c *gin.Context) {
 // @Param limit query string false "Limit"
 // @Param page query string false "Page"
 // @Success 200 {object} []company.CompanyResponse
-// @Failure 400 {object} string
+// @Failure 400 {object} entity.Error
 // @Router /companies/admin/all [get]
 func (h *Handler) GetAllCompaniesA(c *gin.Context) {
 	limit := c.Query("limit")
@@ -219,13 +220,13 @@ func (h *Handler) GetAllCompaniesA(c *gin.Context) {
 	// Convert limit and page to integers and calculate offset
 	limitInt, err := strconv.Atoi(limit)
 	if err != nil || limitInt <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit value"})
+		response.Error(c, http.StatusBadRequest, "Invalid limit value")
 		return
 	}
 
 	pageInt, err := strconv.Atoi(page)
 	if err != nil || pageInt <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page value"})
+		response.Error(c, http.StatusBadRequest, "Invalid page value")
 		return
 	}
 	offset := (pageInt - 1) * limitInt
@@ -236,8 +237,8 @@ func (h *Handler) GetAllCompaniesA(c *gin.Context) {
 	}
 	res, err := h.CompanyClient.ListCompanies(c, req)
 	if err != nil {
-		h.log.Error(fmt.Sprintf("GetAllCompanies request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("GetAllCompanies request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -253,7 +254,7 @@ func (h *Handler) GetAllCompaniesA(c *gin.Context) {
 // @Param page query string false "Page"
 // @Param name query string false "Name"
 // @Success 200 {array} company.ListCompanyUsersResponse
-// @Failure 400 {object} string
+// @Failure 400 {object} entity.Error
 // @Router /companies/users [get]
 func (h *Handler) ListCompanyUsers(c *gin.Context) {
 	limit := c.Query("limit")
@@ -267,20 +268,20 @@ func (h *Handler) ListCompanyUsers(c *gin.Context) {
 	}
 	limitInt, err := strconv.Atoi(limit)
 	if err != nil || limitInt <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit value"})
+		response.Error(c, http.StatusBadRequest, "Invalid limit value")
 		return
 	}
 
 	pageInt, err := strconv.Atoi(page)
 	if err != nil || pageInt <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page value"})
+		response.Error(c, http.StatusBadRequest, "Invalid page value")
 		return
 	}
 	req := &company.ListCompanyUsersRequest{CompanyId: c.MustGet("company_id").(string), Limit: int32(limitInt), Page: int32(pageInt), Name: name}
 	res, err := h.CompanyClient.ListCompanyUsers(c, req)
 	if err != nil {
-		h.log.Error(fmt.Sprintf("ListCompanyUsers request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("ListCompanyUsers request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -296,7 +297,7 @@ func (h *Handler) ListCompanyUsers(c *gin.Context) {
 // @Param limit query string false "Limit"
 // @Param page query string false "Page"
 // @Success 200 {array} company.ListCompanyUsersResponse
-// @Failure 400 {object} string
+// @Failure 400 {object} entity.Error
 // @Router /companies/admin/{company_id}/users [get]
 func (h *Handler) ListCompanyUsersA(c *gin.Context) {
 	limit := c.Query("limit")
@@ -309,20 +310,20 @@ func (h *Handler) ListCompanyUsersA(c *gin.Context) {
 	}
 	limitInt, err := strconv.Atoi(limit)
 	if err != nil || limitInt <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit value"})
+		response.Error(c, http.StatusBadRequest, "Invalid limit value")
 		return
 	}
 
 	pageInt, err := strconv.Atoi(page)
 	if err != nil || pageInt <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page value"})
+		response.Error(c, http.StatusBadRequest, "Invalid page value")
 		return
 	}
 	req := &company.ListCompanyUsersRequest{CompanyId: c.Param("company_id"), Limit: int32(limitInt), Page: int32(pageInt)}
 	res, err := h.CompanyClient.ListCompanyUsers(c, req)
 	if err != nil {
-		h.log.Error(fmt.Sprintf("ListCompanyUsers request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("ListCompanyUsers request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -336,12 +337,12 @@ func (h *Handler) ListCompanyUsersA(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Param input body entity.CreateUserToCompanyRequest true "Company user details"
 // @Success 200 {object} company.Message
-// @Failure 400 {object} string
+// @Failure 400 {object} entity.Error
 // @Router /companies/users [post]
 func (h *Handler) CreateCompanyUser(c *gin.Context) {
 	var req company.CreateUserToCompanyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
@@ -351,8 +352,8 @@ func (h *Handler) CreateCompanyUser(c *gin.Context) {
 
 	res, err := h.CompanyClient.CreateUserToCompany(c, &company.CreateUserToCompanyRequest{CompanyId: c.MustGet("company_id").(string), FirstName: req.FirstName, LastName: req.LastName, Email: req.Email, Role: req.Role, Username: req.Username, Password: req.Password, PhoneNumber: req.PhoneNumber})
 	if err != nil {
-		h.log.Error(fmt.Sprintf("CreateCompanyUser request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("CreateCompanyUser request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -367,18 +368,18 @@ func (h *Handler) CreateCompanyUser(c *gin.Context) {
 // @Param company_id path string true "Company ID"
 // @Param input body entity.CreateUserToCompanyRequest true "Company user details"
 // @Success 200 {object} company.Message
-// @Failure 400 {object} string
+// @Failure 400 {object} entity.Error
 // @Router /companies/admin/{company_id}/users [post]
 func (h *Handler) CreateCompanyUserA(c *gin.Context) {
 	var req entity.CreateUserToCompanyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 	res, err := h.CompanyClient.CreateUserToCompany(c, &company.CreateUserToCompanyRequest{CompanyId: c.Param("company_id"), FirstName: req.FirstName, LastName: req.LastName, Email: req.Email, Role: req.Role, Username: req.Username, Password: req.Password, PhoneNumber: req.PhoneNumber})
 	if err != nil {
-		h.log.Error(fmt.Sprintf("CreateCompanyUser request error: %v", err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, fmt.Sprintf("CreateCompanyUser request error: %v", err))
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
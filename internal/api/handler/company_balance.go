@@ -45,7 +45,7 @@ package handler
 //
 //	res, err := h.CompanyClient.CreateCompanyBalance(ctx, &req)
 //	if err != nil {
-//		h.log.Error("CreateCompanyBalance error: ", err)
+//		h.log.ErrorContext(c, "CreateCompanyBalance error: ", err)
 //		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 //		return
 //	}
@@ -78,7 +78,7 @@ package handler
 //
 //	res, err := h.CompanyClient.GetCompanyBalance(ctx, &company.Id{Id: companyID})
 //	if err != nil {
-//		h.log.Error("GetCompanyBalance error: ", err)
+//		h.log.ErrorContext(c, "GetCompanyBalance error: ", err)
 //		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 //		return
 //	}
@@ -123,7 +123,7 @@ package handler
 //
 //	res, err := h.CompanyClient.UpdateCompanyBalance(ctx, &req)
 //	if err != nil {
-//		h.log.Error("UpdateCompanyBalance error: ", err)
+//		h.log.ErrorContext(c, "UpdateCompanyBalance error: ", err)
 //		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 //		return
 //	}
@@ -170,7 +170,7 @@ package handler
 //
 //	res, err := h.CompanyClient.GetUsersBalanceList(ctx, req)
 //	if err != nil {
-//		h.log.Error("GetUsersBalanceList error: ", err)
+//		h.log.ErrorContext(c, "GetUsersBalanceList error: ", err)
 //		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 //		return
 //	}
@@ -203,7 +203,7 @@ package handler
 //
 //	res, err := h.CompanyClient.DeleteCompanyBalance(ctx, &company.Id{Id: companyID})
 //	if err != nil {
-//		h.log.Error("DeleteCompanyBalance error: ", err)
+//		h.log.ErrorContext(c, "DeleteCompanyBalance error: ", err)
 //		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 //		return
 //	}
@@ -253,7 +253,7 @@ package handler
 //
 //	res, err := h.CompanyClient.SendSMS(ctx, &smsReq)
 //	if err != nil {
-//		h.log.Error("SendSMS error: ", err)
+//		h.log.ErrorContext(c, "SendSMS error: ", err)
 //		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 //		return
 //	}
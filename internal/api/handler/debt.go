@@ -3,11 +3,12 @@ package handler
 import (
 	"bytes"
 	"context"
+	"gateway/internal/api/response"
+	"gateway/internal/entity"
 	"gateway/internal/generated/debts"
 	pbu "gateway/internal/generated/user"
 	"github.com/gin-gonic/gin"
 	"github.com/xuri/excelize/v2"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -22,25 +23,26 @@ import (
 // @Produce json
 // @Param data body entity.DebtsRequest true "Debtor details"
 // @Success 201 {object} debts.Debts "Created debtor record"
-// @Failure 400 {object} products.Error "Invalid input"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid input"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /debts [post]
 func (h *Handler) CreateDebt(c *gin.Context) {
-	var req debts.DebtsRequest
+	var body entity.DebtsRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Invalid request data", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "Invalid request data", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	req := body.Proto()
 
 	req.CompanyId = c.MustGet("company_id").(string)
 	req.DebtType = "debtor"
 
-	res, err := h.DebtClient.CreateDebts(c, &req)
+	res, err := h.DebtClient.CreateDebts(c, req)
 	if err != nil {
-		h.log.Error("Error creating debt", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error creating debt", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -56,8 +58,8 @@ func (h *Handler) CreateDebt(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Debtor ID"
 // @Success 200 {object} debts.Debts "Debtor details"
-// @Failure 400 {object} products.Error "Invalid ID"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid ID"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /debts/{id} [get]
 func (h *Handler) GetDebt(c *gin.Context) {
 	id := c.Param("id")
@@ -69,8 +71,8 @@ func (h *Handler) GetDebt(c *gin.Context) {
 
 	res, err := h.DebtClient.GetDebts(c, req)
 	if err != nil {
-		h.log.Error("Error fetching debt", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching debt", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -91,8 +93,8 @@ func (h *Handler) GetDebt(c *gin.Context) {
 // @Param limit query int false "Maximum results"
 // @Param page query int false "Page number for pagination"
 // @Success 200 {object} debts.DebtsList "List of debtor records"
-// @Failure 400 {object} products.Error "Invalid filter value"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid filter value"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /debts [get]
 func (h *Handler) GetListDebts(c *gin.Context) {
 	var filter debts.FilterDebts
@@ -107,7 +109,7 @@ func (h *Handler) GetListDebts(c *gin.Context) {
 
 	// Validate is_fully_pay value
 	if filter.IsFullyPay != "true" && filter.IsFullyPay != "false" && filter.IsFullyPay != "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid value for is_fully_pay"})
+		response.Error(c, http.StatusBadRequest, "invalid value for is_fully_pay")
 		return
 	}
 
@@ -119,8 +121,8 @@ func (h *Handler) GetListDebts(c *gin.Context) {
 		if limit, err := strconv.ParseInt(limitStr, 10, 64); err == nil {
 			filter.Limit = int32(limit)
 		} else {
-			h.log.Error("Invalid limit parameter", "value", limitStr, "error", err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+			h.log.ErrorContext(c, "Invalid limit parameter", "value", limitStr, "error", err.Error())
+			response.Error(c, http.StatusBadRequest, "Invalid limit parameter")
 			return
 		}
 	}
@@ -128,8 +130,8 @@ func (h *Handler) GetListDebts(c *gin.Context) {
 		if page, err := strconv.ParseInt(pageStr, 10, 64); err == nil {
 			filter.Page = int32(page)
 		} else {
-			h.log.Error("Invalid page parameter", "value", pageStr, "error", err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page parameter"})
+			h.log.ErrorContext(c, "Invalid page parameter", "value", pageStr, "error", err.Error())
+			response.Error(c, http.StatusBadRequest, "Invalid page parameter")
 			return
 		}
 	}
@@ -139,8 +141,8 @@ func (h *Handler) GetListDebts(c *gin.Context) {
 
 	res, err := h.DebtClient.GetListDebts(c, &filter)
 	if err != nil {
-		h.log.Error("Error fetching debt list", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching debt list", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -154,7 +156,7 @@ func (h *Handler) GetListDebts(c *gin.Context) {
 			res.Installments[i].ClientName = client.FullName
 			res.Installments[i].ClientPhone = client.Phone
 		} else {
-			h.log.Error("Error fetching client info", "client_id", debt.ClientId, "error", err.Error())
+			h.log.ErrorContext(c, "Error fetching client info", "client_id", debt.ClientId, "error", err.Error())
 			res.Installments[i].ClientName = "Unknown"
 			res.Installments[i].ClientPhone = "Unknown"
 		}
@@ -172,8 +174,8 @@ func (h *Handler) GetListDebts(c *gin.Context) {
 // @Produce json
 // @Param client_id path string true "Client ID"
 // @Success 200 {object} debts.DebtsList "Debtor records for the client"
-// @Failure 400 {object} products.Error "Invalid client ID"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid client ID"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /debts/client/{client_id} [get]
 func (h *Handler) GetClientDebts(c *gin.Context) {
 	clientID := c.Param("client_id")
@@ -185,8 +187,8 @@ func (h *Handler) GetClientDebts(c *gin.Context) {
 
 	res, err := h.DebtClient.GetClientDebts(c, req)
 	if err != nil {
-		h.log.Error("Error fetching client debts", "client_id", clientID, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching client debts", "client_id", clientID, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -202,25 +204,26 @@ func (h *Handler) GetClientDebts(c *gin.Context) {
 // @Produce json
 // @Param data body entity.PayDebtReq true "Payment details"
 // @Success 200 {object} debts.Debts "Updated debtor record"
-// @Failure 400 {object} products.Error "Invalid input"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid input"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /debts/pay [post]
 func (h *Handler) PayDebt(c *gin.Context) {
-	var req debts.PayDebtsReq
+	var body entity.PayDebtReq
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Invalid request data", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "Invalid request data", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	req := body.Proto()
 
 	req.CompanyId = c.MustGet("company_id").(string)
 	req.PayType = "in"
 
-	res, err := h.DebtClient.PayDebts(c, &req)
+	res, err := h.DebtClient.PayDebts(c, req)
 	if err != nil {
-		h.log.Error("Error processing payment", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error processing payment", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -236,8 +239,8 @@ func (h *Handler) PayDebt(c *gin.Context) {
 // @Produce json
 // @Param debt_id path string true "Debtor ID"
 // @Success 200 {object} debts.PaymentList "List of payments"
-// @Failure 400 {object} products.Error "Invalid debtor ID"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid debtor ID"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /debts/payments/{debt_id} [get]
 func (h *Handler) GetPaymentsByDebtId(c *gin.Context) {
 	debtId := c.Param("debt_id")
@@ -248,8 +251,8 @@ func (h *Handler) GetPaymentsByDebtId(c *gin.Context) {
 
 	res, err := h.DebtClient.GetPaymentsByDebtsId(c, req)
 	if err != nil {
-		h.log.Error("Error fetching payments for debt", "debt_id", debtId, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching payments for debt", "debt_id", debtId, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -265,8 +268,8 @@ func (h *Handler) GetPaymentsByDebtId(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Payment ID"
 // @Success 200 {object} debts.Payment "Payment details"
-// @Failure 400 {object} products.Error "Invalid payment ID"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid payment ID"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /debts/payment/{id} [get]
 func (h *Handler) GetPayment(c *gin.Context) {
 	id := c.Param("id")
@@ -278,8 +281,8 @@ func (h *Handler) GetPayment(c *gin.Context) {
 
 	res, err := h.DebtClient.GetPayment(c, req)
 	if err != nil {
-		h.log.Error("Error fetching payment", "payment_id", id, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching payment", "payment_id", id, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -294,8 +297,8 @@ func (h *Handler) GetPayment(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} debts.SumMoney "Total debtor sum"
-// @Failure 400 {object} products.Error "Bad request"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Bad request"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /debts/total-sum [get]
 func (h *Handler) GetTotalDebtSum(c *gin.Context) {
 	companyID := c.MustGet("company_id").(string)
@@ -307,8 +310,8 @@ func (h *Handler) GetTotalDebtSum(c *gin.Context) {
 
 	res, err := h.DebtClient.GetTotalDebtSum(c, &req)
 	if err != nil {
-		h.log.Error("Error fetching total debt sum", "company_id", companyID, "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching total debt sum", "company_id", companyID, "error", err)
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -324,8 +327,8 @@ func (h *Handler) GetTotalDebtSum(c *gin.Context) {
 // @Produce json
 // @Param user_id path string true "User ID"
 // @Success 200 {object} debts.SumMoney "User's total debtor sum"
-// @Failure 400 {object} products.Error "Invalid user ID"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid user ID"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /debts/total-sum/{user_id} [get]
 func (h *Handler) GetUserTotalDebt(c *gin.Context) {
 	userID := c.Param("user_id")
@@ -339,8 +342,8 @@ func (h *Handler) GetUserTotalDebt(c *gin.Context) {
 
 	res, err := h.DebtClient.GetUserTotalDebtSum(c, &req)
 	if err != nil {
-		h.log.Error("Error fetching user total debt sum", "user_id", userID, "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching user total debt sum", "user_id", userID, "error", err)
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -356,27 +359,27 @@ func (h *Handler) GetUserTotalDebt(c *gin.Context) {
 // @Produce json
 // @Param user_id path string true "User ID"
 // @Success 200 {object} debts.UserPaymentsRes "User payment records"
-// @Failure 400 {object} products.Error "Invalid user ID"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid user ID"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /debts/payments/{user_id} [get]
 func (h *Handler) GetUserPayments(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		h.log.Error("user_id not provided in URL")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+		h.log.ErrorContext(c, "user_id not provided in URL")
+		response.Error(c, http.StatusBadRequest, "user_id is required")
 		return
 	}
 
 	companyVal, exists := c.Get("company_id")
 	if !exists {
-		h.log.Error("company_id not found in context")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id is required"})
+		h.log.ErrorContext(c, "company_id not found in context")
+		response.Error(c, http.StatusBadRequest, "company_id is required")
 		return
 	}
 	companyID, ok := companyVal.(string)
 	if !ok || companyID == "" {
-		h.log.Error("company_id is not a valid string")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid company_id"})
+		h.log.ErrorContext(c, "company_id is not a valid string")
+		response.Error(c, http.StatusBadRequest, "invalid company_id")
 		return
 	}
 
@@ -388,8 +391,8 @@ func (h *Handler) GetUserPayments(c *gin.Context) {
 
 	res, err := h.DebtClient.GetUserPayments(c, req)
 	if err != nil {
-		h.log.Error("Error fetching user payments", "user_id", userID, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		h.log.ErrorContext(c, "Error fetching user payments", "user_id", userID, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -415,8 +418,8 @@ func (h *Handler) GetDebtsInExcel(c *gin.Context) {
 
 	res, err := h.DebtClient.GetDebtsForExel(c, &req)
 	if err != nil {
-		h.log.Error("Error fetching debts for excel", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching debts for excel", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -443,8 +446,8 @@ func (h *Handler) GetDebtsInExcel(c *gin.Context) {
 
 	var buf bytes.Buffer
 	if err := f.Write(&buf); err != nil {
-		h.log.Error("Error writing Excel file", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		h.log.ErrorContext(c, "Error writing Excel file", "error", err.Error())
+		response.Error(c, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
@@ -463,25 +466,26 @@ func (h *Handler) GetDebtsInExcel(c *gin.Context) {
 // @Produce json
 // @Param data body entity.DebtsRequest true "Creditor details"
 // @Success 201 {object} debts.Debts "Created creditor record"
-// @Failure 400 {object} products.Error "Invalid input"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid input"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /creditor [post]
 func (h *Handler) CreateCreditor(c *gin.Context) {
-	var req debts.DebtsRequest
+	var body entity.DebtsRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Invalid request data", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "Invalid request data", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	req := body.Proto()
 
 	req.CompanyId = c.MustGet("company_id").(string)
 	req.DebtType = "creditor"
 
-	res, err := h.DebtClient.CreateDebts(c, &req)
+	res, err := h.DebtClient.CreateDebts(c, req)
 	if err != nil {
-		h.log.Error("Error creating creditor", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error creating creditor", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -497,8 +501,8 @@ func (h *Handler) CreateCreditor(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Creditor ID"
 // @Success 200 {object} debts.Debts "Creditor details"
-// @Failure 400 {object} products.Error "Invalid ID"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid ID"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /creditor/{id} [get]
 func (h *Handler) GetCreditors(c *gin.Context) {
 	id := c.Param("id")
@@ -510,8 +514,8 @@ func (h *Handler) GetCreditors(c *gin.Context) {
 
 	res, err := h.DebtClient.GetDebts(c, req)
 	if err != nil {
-		h.log.Error("Error fetching creditor", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching creditor", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -532,8 +536,8 @@ func (h *Handler) GetCreditors(c *gin.Context) {
 // @Param limit query int false "Maximum results"
 // @Param page query int false "Page number for pagination"
 // @Success 200 {object} debts.DebtsList "List of creditor records"
-// @Failure 400 {object} products.Error "Invalid filter value"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid filter value"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /creditor [get]
 func (h *Handler) GetListCreditors(c *gin.Context) {
 	var filter debts.FilterDebts
@@ -547,7 +551,7 @@ func (h *Handler) GetListCreditors(c *gin.Context) {
 	pageStr := c.Query("page")
 
 	if filter.IsFullyPay != "true" && filter.IsFullyPay != "false" && filter.IsFullyPay != "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid value for is_fully_pay"})
+		response.Error(c, http.StatusBadRequest, "invalid value for is_fully_pay")
 		return
 	}
 
@@ -559,8 +563,8 @@ func (h *Handler) GetListCreditors(c *gin.Context) {
 		if limit, err := strconv.ParseInt(limitStr, 10, 64); err == nil {
 			filter.Limit = int32(limit)
 		} else {
-			h.log.Error("Invalid limit parameter", "value", limitStr, "error", err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+			h.log.ErrorContext(c, "Invalid limit parameter", "value", limitStr, "error", err.Error())
+			response.Error(c, http.StatusBadRequest, "Invalid limit parameter")
 			return
 		}
 	}
@@ -568,8 +572,8 @@ func (h *Handler) GetListCreditors(c *gin.Context) {
 		if page, err := strconv.ParseInt(pageStr, 10, 64); err == nil {
 			filter.Page = int32(page)
 		} else {
-			h.log.Error("Invalid page parameter", "value", pageStr, "error", err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page parameter"})
+			h.log.ErrorContext(c, "Invalid page parameter", "value", pageStr, "error", err.Error())
+			response.Error(c, http.StatusBadRequest, "Invalid page parameter")
 			return
 		}
 	}
@@ -579,8 +583,8 @@ func (h *Handler) GetListCreditors(c *gin.Context) {
 
 	res, err := h.DebtClient.GetListDebts(c, &filter)
 	if err != nil {
-		h.log.Error("Error fetching creditor list", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching creditor list", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -594,7 +598,7 @@ func (h *Handler) GetListCreditors(c *gin.Context) {
 			res.Installments[i].ClientName = client.FullName
 			res.Installments[i].ClientPhone = client.Phone
 		} else {
-			h.log.Error("Error fetching supplier info", "client_id", debt.ClientId, "error", err.Error())
+			h.log.ErrorContext(c, "Error fetching supplier info", "client_id", debt.ClientId, "error", err.Error())
 			res.Installments[i].ClientName = "Unknown"
 			res.Installments[i].ClientPhone = "Unknown"
 		}
@@ -612,13 +616,13 @@ func (h *Handler) GetListCreditors(c *gin.Context) {
 // @Produce json
 // @Param supplier_id path string true "Supplier ID"
 // @Success 200 {object} debts.DebtsList "Creditor records for the supplier"
-// @Failure 400 {object} products.Error "Invalid supplier ID"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid supplier ID"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /creditor/supplier/{supplier_id} [get]
 func (h *Handler) GetCreditsFromSupplier(c *gin.Context) {
 	supplierID := c.Param("supplier_id")
 	if supplierID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "supplier_id is required"})
+		response.Error(c, http.StatusBadRequest, "supplier_id is required")
 		return
 	}
 
@@ -628,12 +632,12 @@ func (h *Handler) GetCreditsFromSupplier(c *gin.Context) {
 		DebtType:  "creditor", // исправлено: кредитор, а не должник
 	}
 
-	log.Println(supplierID)
+	h.log.DebugContext(c, "Fetching supplier creditor records", "supplier_id", supplierID)
 
 	res, err := h.DebtClient.GetClientDebts(c, req)
 	if err != nil {
-		h.log.Error("Error fetching supplier creditor records", "supplier_id", supplierID, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching supplier creditor records", "supplier_id", supplierID, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -647,27 +651,28 @@ func (h *Handler) GetCreditsFromSupplier(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
-// @Param data body debts.PayDebtsReq true "Payment details"
+// @Param data body entity.PayDebtReq true "Payment details"
 // @Success 200 {object} debts.Debts "Updated creditor record"
-// @Failure 400 {object} products.Error "Invalid input"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid input"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /creditor/pay [post]
 func (h *Handler) PayCredit(c *gin.Context) {
-	var req debts.PayDebtsReq
+	var body entity.PayDebtReq
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Invalid request data", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "Invalid request data", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	req := body.Proto()
 
 	req.CompanyId = c.MustGet("company_id").(string)
 	req.PayType = "out" // для кредитора платежи исходящие
 
-	res, err := h.DebtClient.PayDebts(c, &req)
+	res, err := h.DebtClient.PayDebts(c, req)
 	if err != nil {
-		h.log.Error("Error processing creditor payment", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error processing creditor payment", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -683,13 +688,13 @@ func (h *Handler) PayCredit(c *gin.Context) {
 // @Produce     json
 // @Param       credit_id path string true "Creditor ID"           // renamed from supplier_id
 // @Success     200       {object} debts.PaymentList "List of payments"
-// @Failure     400       {object} products.Error     "Invalid creditor ID format"
-// @Failure     500       {object} products.Error     "Server error"
+// @Failure     400       {object} entity.Error     "Invalid creditor ID format"
+// @Failure     500       {object} entity.Error     "Server error"
 // @Router      /creditor/payments/{credit_id} [get]
 func (h *Handler) GetPaymentsByCreditId(c *gin.Context) {
 	creditId := c.Param("credit_id")
 	if creditId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "credit_id is required"})
+		response.Error(c, http.StatusBadRequest, "credit_id is required")
 		return
 	}
 
@@ -700,8 +705,8 @@ func (h *Handler) GetPaymentsByCreditId(c *gin.Context) {
 
 	res, err := h.DebtClient.GetPaymentsByDebtsId(c, req)
 	if err != nil {
-		h.log.Error("Error fetching payments for creditor", "credit_id", creditId, "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching payments for creditor", "credit_id", creditId, "error", err)
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -717,8 +722,8 @@ func (h *Handler) GetPaymentsByCreditId(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Payment ID"
 // @Success 200 {object} debts.Payment "Payment details"
-// @Failure 400 {object} products.Error "Invalid payment ID"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid payment ID"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /creditor/payment/{id} [get]
 func (h *Handler) GetCreditPayment(c *gin.Context) {
 	id := c.Param("id")
@@ -730,8 +735,8 @@ func (h *Handler) GetCreditPayment(c *gin.Context) {
 
 	res, err := h.DebtClient.GetPayment(c, req)
 	if err != nil {
-		h.log.Error("Error fetching creditor payment", "payment_id", id, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching creditor payment", "payment_id", id, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -746,8 +751,8 @@ func (h *Handler) GetCreditPayment(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} debts.SumMoney "Total creditor sum"
-// @Failure 400 {object} products.Error "Bad request"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Bad request"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /creditor/total-sum [get]
 func (h *Handler) GetTotalCreditSum(c *gin.Context) {
 	companyID := c.MustGet("company_id").(string)
@@ -759,8 +764,8 @@ func (h *Handler) GetTotalCreditSum(c *gin.Context) {
 
 	res, err := h.DebtClient.GetTotalDebtSum(c, &req)
 	if err != nil {
-		h.log.Error("Error fetching total creditor sum", "company_id", companyID, "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching total creditor sum", "company_id", companyID, "error", err)
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -776,8 +781,8 @@ func (h *Handler) GetTotalCreditSum(c *gin.Context) {
 // @Produce json
 // @Param supplier_id path string true "Supplier ID"
 // @Success 200 {object} debts.SumMoney "Supplier's total creditor sum"
-// @Failure 400 {object} products.Error "Invalid supplier ID"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid supplier ID"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /creditor/total-sum/{supplier_id} [get]
 func (h *Handler) GetTotalCreditFromSupplier(c *gin.Context) {
 	supplierID := c.Param("supplier_id")
@@ -791,8 +796,8 @@ func (h *Handler) GetTotalCreditFromSupplier(c *gin.Context) {
 
 	res, err := h.DebtClient.GetUserTotalDebtSum(c, &req)
 	if err != nil {
-		h.log.Error("Error fetching supplier total creditor sum", "supplier_id", supplierID, "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching supplier total creditor sum", "supplier_id", supplierID, "error", err)
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -808,27 +813,27 @@ func (h *Handler) GetTotalCreditFromSupplier(c *gin.Context) {
 // @Produce json
 // @Param supplier_id path string true "Supplier ID"
 // @Success 200 {object} debts.UserPaymentsRes "Supplier payment records"
-// @Failure 400 {object} products.Error "Invalid supplier ID"
-// @Failure 500 {object} products.Error "Server error"
+// @Failure 400 {object} entity.Error "Invalid supplier ID"
+// @Failure 500 {object} entity.Error "Server error"
 // @Router /creditor/pay/{supplier_id} [get]
 func (h *Handler) GetPaymentsToSupplier(c *gin.Context) {
 	supplierID := c.Param("supplier_id")
 	if supplierID == "" {
-		h.log.Error("supplier_id not provided in URL")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "supplier_id is required"})
+		h.log.ErrorContext(c, "supplier_id not provided in URL")
+		response.Error(c, http.StatusBadRequest, "supplier_id is required")
 		return
 	}
 
 	companyVal, exists := c.Get("company_id")
 	if !exists {
-		h.log.Error("company_id not found in context")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id is required"})
+		h.log.ErrorContext(c, "company_id not found in context")
+		response.Error(c, http.StatusBadRequest, "company_id is required")
 		return
 	}
 	companyID, ok := companyVal.(string)
 	if !ok || companyID == "" {
-		h.log.Error("company_id is not a valid string")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid company_id"})
+		h.log.ErrorContext(c, "company_id is not a valid string")
+		response.Error(c, http.StatusBadRequest, "invalid company_id")
 		return
 	}
 
@@ -840,8 +845,8 @@ func (h *Handler) GetPaymentsToSupplier(c *gin.Context) {
 
 	res, err := h.DebtClient.GetUserPayments(c, req)
 	if err != nil {
-		h.log.Error("Error fetching supplier payments", "supplier_id", supplierID, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		h.log.ErrorContext(c, "Error fetching supplier payments", "supplier_id", supplierID, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
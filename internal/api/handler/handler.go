@@ -1,12 +1,20 @@
 package handler
 
 import (
+	"fmt"
 	"gateway/config"
+	"gateway/internal/api/branch"
+	"gateway/internal/api/token"
+	"gateway/internal/audit"
 	pbc "gateway/internal/generated/company"
 	pbd "gateway/internal/generated/debts"
 	pbp "gateway/internal/generated/products"
 	pbu "gateway/internal/generated/user"
+	"gateway/internal/health"
+	"gateway/internal/minio"
+	"gateway/internal/rbac"
 	"log/slog"
+	"time"
 
 	"gateway/pkg"
 )
@@ -16,15 +24,39 @@ type Handler struct {
 	ProductClient pbp.ProductsClient
 	CompanyClient pbc.CompanyServiceClient
 	DebtClient    pbd.DebtsServiceClient
+	Media         *minio.Client
+	Health        *health.Checker
+	Branches      *branch.Authorizer
+	Roles         *rbac.Manager
+	Policy        *rbac.Policy
+	Ownership     *rbac.Ownership
+	Audit         *audit.Trail
+	Tokens        *token.Manager
 	log           *slog.Logger
 }
 
-func NewHandlerRepo(cfg *config.Config, log *slog.Logger) *Handler {
+func NewHandlerRepo(cfg *config.Config, log *slog.Logger, conns *pkg.Conns, media *minio.Client, checker *health.Checker,
+	policy *rbac.Policy, ownership *rbac.Ownership, trail *audit.Trail, tokens *token.Manager) (*Handler, error) {
+	assignments, err := branch.NewFileStore(cfg.BRANCH_ASSIGNMENTS_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("loading branch assignments from %s: %w", cfg.BRANCH_ASSIGNMENTS_FILE, err)
+	}
+
+	companyClient := pkg.NewCompanyClient(conns)
+
 	return &Handler{
-		UserClient:    pkg.NewUserClient(cfg),
-		ProductClient: pkg.NewProductClient(cfg),
-		CompanyClient: pkg.NewCompanyClient(cfg),
-		DebtClient:    pkg.NewDebtClient(cfg),
+		UserClient:    pkg.NewUserClient(conns),
+		ProductClient: pkg.NewProductClient(conns),
+		CompanyClient: companyClient,
+		DebtClient:    pkg.NewDebtClient(conns),
+		Media:         media,
+		Health:        checker,
+		Branches:      branch.NewAuthorizer(companyClient, assignments, time.Second*time.Duration(cfg.BRANCH_CACHE_TTL), cfg.BRANCH_ALLOW_UNASSIGNED),
+		Roles:         rbac.NewManager(policy),
+		Policy:        policy,
+		Ownership:     ownership,
+		Audit:         trail,
+		Tokens:        tokens,
 		log:           log,
-	}
+	}, nil
 }
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"gateway/internal/health"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// Healthz godoc
+// @Summary Liveness probe
+// @Description Report that the gateway process is up. It does not check any dependency.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} company.Message
+// @Router /healthz [get]
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readyz godoc
+// @Summary Readiness probe
+// @Description Probe the user, product, company and debt services, MinIO and Kafka and report the status and latency of each. Returns 503 when a critical dependency (READINESS_CRITICAL) is down.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *Handler) Readyz(c *gin.Context) {
+	report := h.Health.Check(c.Request.Context())
+
+	if !report.Ready() {
+		h.log.ErrorContext(c, "Readiness check failed", "report", report.Checks)
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"gateway/internal/api/token"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying access tokens issued by the gateway, selected by the token's kid header.
+// @Description The set is empty while tokens are signed with the shared HS256 secret.
+// @Tags User
+// @Produce json
+// @Success 200 {object} token.JWKS
+// @Router /.well-known/jwks.json [get]
+func (a *Handler) JWKS(c *gin.Context) {
+	keys := a.Tokens.Keys()
+	if keys == nil {
+		c.JSON(http.StatusOK, token.JWKS{Keys: []token.JWK{}})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, keys.JWKS())
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"gateway/internal/api/response"
+	"gateway/internal/rbac"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// MePermissions describes what the caller's token may do.
+type MePermissions struct {
+	Id        string `json:"id"`
+	Role      string `json:"role"`
+	CompanyId string `json:"company_id"`
+	// Roles are the company roles of the user; when set they replace Role.
+	Roles []string `json:"roles"`
+	// Branches are the branches the user may address with the branch_id
+	// header. It is empty for admins, who may address any branch.
+	Branches []string `json:"branches"`
+	// Permissions maps a resource to the routes the user may call on it.
+	Permissions map[string][]rbac.Permission `json:"permissions"`
+}
+
+// GetMyPermissions godoc
+// @Summary My permissions
+// @Description Return the caller's claims, allowed branches and the routes they may call, grouped by resource
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} MePermissions
+// @Failure 401 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /me/permissions [get]
+func (h *Handler) GetMyPermissions(c *gin.Context) {
+	res := MePermissions{
+		Id:        c.GetString("id"),
+		Role:      c.GetString("role"),
+		CompanyId: c.GetString("company_id"),
+		Roles:     h.Roles.UserRoles(c.GetString("company_id"), c.GetString("id")),
+		Branches:  []string{},
+	}
+
+	if res.Role != "admin" {
+		branches, err := h.Branches.Allowed(c.Request.Context(), res.CompanyId, res.Id, res.Role)
+		if err != nil {
+			h.log.ErrorContext(c, "Error resolving allowed branches", "error", err.Error())
+			response.FromGRPC(c, err)
+			return
+		}
+		if branches != nil {
+			res.Branches = branches
+		}
+	}
+
+	permissions, err := h.Policy.Allowed(res.Id, res.Role, res.CompanyId)
+	if err != nil {
+		h.log.ErrorContext(c, "Error resolving permissions", "error", err.Error())
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	res.Permissions = permissions
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"errors"
+	"gateway/internal/api/response"
+	"gateway/internal/generated/company"
+	"gateway/internal/rbac"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// ListPolicies godoc
+// @Summary List policy rules
+// @Description List the policy (p) and grouping (g) rules of the enforcer, optionally filtered by subject and domain (company ID or "*")
+// @Tags Policies
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param subject query string false "Subject"
+// @Param domain query string false "Domain"
+// @Success 200 {array} rbac.Rule
+// @Failure 500 {object} entity.Error
+// @Router /policies [get]
+func (h *Handler) ListPolicies(c *gin.Context) {
+	rules, err := h.Policy.Rules(c.Query("subject"), c.Query("domain"))
+	if err != nil {
+		h.log.ErrorContext(c, "Error listing policies", "error", err.Error())
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// AddPolicy godoc
+// @Summary Add policy rule
+// @Description Add a runtime rule: type "p" with [sub, dom, obj, act] or type "g" with [user, role, dom]
+// @Tags Policies
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Rule body rbac.Rule true "Rule"
+// @Success 201 {object} rbac.Rule
+// @Failure 400 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /policies [post]
+func (h *Handler) AddPolicy(c *gin.Context) {
+	var req rbac.Rule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Invalid(c, err)
+		return
+	}
+
+	if err := h.Policy.AddRule(req); err != nil {
+		h.policyError(c, "Error adding policy", err)
+		return
+	}
+
+	h.log.InfoContext(c, "Policy rule added", "by", c.GetString("id"), "type", req.Type, "rule", req.Rule)
+
+	c.JSON(http.StatusCreated, req)
+}
+
+// RemovePolicy godoc
+// @Summary Remove policy rule
+// @Description Remove a runtime rule. Built-in rules from policy.csv cannot be removed here.
+// @Tags Policies
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Rule body rbac.Rule true "Rule"
+// @Success 200 {object} rbac.Rule
+// @Failure 400 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /policies [delete]
+func (h *Handler) RemovePolicy(c *gin.Context) {
+	var req rbac.Rule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Invalid(c, err)
+		return
+	}
+
+	if err := h.Policy.RemoveRule(req); err != nil {
+		h.policyError(c, "Error removing policy", err)
+		return
+	}
+
+	h.log.InfoContext(c, "Policy rule removed", "by", c.GetString("id"), "type", req.Type, "rule", req.Rule)
+
+	c.JSON(http.StatusOK, req)
+}
+
+// ReloadPolicies godoc
+// @Summary Reload policies
+// @Description Reload the policy from policy.csv and the runtime rules file, and the ownership rules
+// @Tags Policies
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} company.Message
+// @Failure 500 {object} entity.Error
+// @Router /policies/reload [post]
+func (h *Handler) ReloadPolicies(c *gin.Context) {
+	if err := h.Policy.Reload(); err != nil {
+		h.log.ErrorContext(c, "Error reloading policies", "error", err.Error())
+		response.Error(c, http.StatusInternalServerError, "could not reload policies, the previous policy is still in effect")
+		return
+	}
+
+	if err := h.Ownership.Reload(); err != nil {
+		h.log.ErrorContext(c, "Error reloading ownership rules", "error", err.Error())
+		response.Error(c, http.StatusInternalServerError, "could not reload ownership rules, the previous rules are still in effect")
+		return
+	}
+
+	c.JSON(http.StatusOK, &company.Message{Message: "policies reloaded"})
+}
+
+// ExplainPolicy godoc
+// @Summary Explain a policy decision
+// @Description Evaluate a request for a user the way the permission middleware does and show the rule that allowed it or why it was denied
+// @Tags Policies
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id query string false "User ID"
+// @Param role query string true "Role from the user's token"
+// @Param company_id query string false "Company ID"
+// @Param method query string true "HTTP method"
+// @Param path query string true "Request path"
+// @Success 200 {object} rbac.Explanation
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /policies/explain [get]
+func (h *Handler) ExplainPolicy(c *gin.Context) {
+	role, method, path := c.Query("role"), c.Query("method"), c.Query("path")
+	if role == "" || method == "" || path == "" {
+		response.Error(c, http.StatusBadRequest, "role, method and path are required")
+		return
+	}
+
+	res, err := h.Policy.Explain(c.Query("user_id"), role, c.Query("company_id"), method, path)
+	if err != nil {
+		h.log.ErrorContext(c, "Error explaining policy decision", "error", err.Error())
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// ListPolicyVersions godoc
+// @Summary List policy versions
+// @Description List the saved versions of the runtime rules, oldest first
+// @Tags Policies
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} rbac.Version
+// @Router /policies/versions [get]
+func (h *Handler) ListPolicyVersions(c *gin.Context) {
+	c.JSON(http.StatusOK, h.Policy.Versions())
+}
+
+// RollbackPolicies godoc
+// @Summary Roll back policies
+// @Description Restore the runtime rules of a saved version. The rollback itself is saved as a new version.
+// @Tags Policies
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param version path int true "Version ID"
+// @Success 200 {object} company.Message
+// @Failure 400 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /policies/versions/{version}/rollback [post]
+func (h *Handler) RollbackPolicies(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "version must be a number")
+		return
+	}
+
+	if err := h.Policy.Rollback(version); err != nil {
+		h.policyError(c, "Error rolling back policies", err)
+		return
+	}
+
+	h.log.InfoContext(c, "Policies rolled back", "by", c.GetString("id"), "version", version)
+
+	c.JSON(http.StatusOK, &company.Message{Message: "policies rolled back to version " + strconv.Itoa(version)})
+}
+
+// policyError maps errors of the policy store to responses.
+func (h *Handler) policyError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, rbac.ErrRuleNotFound), errors.Is(err, rbac.ErrVersionNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, rbac.ErrRuleExists):
+		response.Error(c, http.StatusConflict, err.Error())
+	case errors.Is(err, rbac.ErrInvalidRule), errors.Is(err, rbac.ErrBuiltinRule):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		h.log.ErrorContext(c, msg, "error", err.Error())
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+	}
+}
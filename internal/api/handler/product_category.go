@@ -1,11 +1,11 @@
 package handler
 
 import (
+	"gateway/internal/api/middleware"
+	"gateway/internal/api/response"
 	"gateway/internal/entity"
 	"gateway/internal/generated/products"
-	"gateway/internal/minio"
 	"github.com/gin-gonic/gin"
-	"log"
 	"net/http"
 	"strconv"
 )
@@ -26,39 +26,35 @@ import (
 // @Router /products/category [post]
 func (h *Handler) CreateCategory(c *gin.Context) {
 
-	branchID := c.GetHeader("branch_id")
+	branchID := c.GetString(middleware.BranchKey)
 	if branchID == "" {
-		h.log.Error("Branch ID is missing in the header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		h.log.ErrorContext(c, "Branch ID is missing in the header")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	req := entity.Names{}
 
 	if err := c.ShouldBind(&req); err != nil {
-		h.log.Error("bind json err", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request", "error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
 	var url string
 	file, err := c.FormFile("file")
 	if err == nil {
-		url, err = minio.UploadMedia(file)
+		url, err = h.Media.UploadMedia(c, file)
 		if err != nil {
-			log.Println("Error occurred while uploading file")
-			h.log.Error("Error occurred while uploading file:", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			h.log.ErrorContext(c, "Error occurred while uploading file", "error", err.Error())
+			response.Error(c, http.StatusBadGateway, "Failed to upload file")
 			return
 		}
 	} else {
 		url = "no image"
-		log.Println("No file uploaded, continuing without an image")
+		h.log.DebugContext(c, "No file uploaded, continuing without an image")
 	}
 
-	log.Println(url)
-	log.Println(url)
-	log.Println(url)
+	h.log.DebugContext(c, "Creating category", "name", req.Name, "image_url", url)
 
 	res, err := h.ProductClient.CreateCategory(c, &products.CreateCategoryRequest{
 		Name:      req.Name,
@@ -68,8 +64,8 @@ func (h *Handler) CreateCategory(c *gin.Context) {
 		BranchId:  branchID,
 	})
 	if err != nil {
-		h.log.Error("Error creating category", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error creating category", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -88,14 +84,14 @@ func (h *Handler) CreateCategory(c *gin.Context) {
 // @Param file formData file false "Upload category image (optional)"
 // @Param name formData string true "Name of the category"
 // @Success 200 {object} products.Category "Category successfully updated"
-// @Failure 400 {object} products.Error "Invalid input or bad request"
-// @Failure 500 {object} products.Error "Internal server error"
+// @Failure 400 {object} entity.Error "Invalid input or bad request"
+// @Failure 500 {object} entity.Error "Internal server error"
 // @Router /products/category/{id} [put]
 func (h *Handler) UpdateCategory(c *gin.Context) {
-	branchID := c.GetHeader("branch_id")
+	branchID := c.GetString(middleware.BranchKey)
 	if branchID == "" {
-		h.log.Error("Branch ID is missing in the header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		h.log.ErrorContext(c, "Branch ID is missing in the header")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -109,25 +105,23 @@ func (h *Handler) UpdateCategory(c *gin.Context) {
 	name := entity.Names{}
 
 	if err := c.ShouldBind(&name); err != nil {
-		h.log.Error("Error parsing UpdateCategory request body", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
-	log.Println(name.Name)
+	h.log.DebugContext(c, "Updating category", "name", name.Name)
 
 	var url string
 	file, err := c.FormFile("file")
 	if err == nil {
-		url, err = minio.UploadMedia(file)
+		url, err = h.Media.UploadMedia(c, file)
 		if err != nil {
-			log.Println("Error occurred while uploading file")
-			h.log.Error("Error occurred while uploading file:", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			h.log.ErrorContext(c, "Error occurred while uploading file", "error", err.Error())
+			response.Error(c, http.StatusBadGateway, "Failed to upload file")
 			return
 		}
 	} else {
-		log.Println("No file uploaded, continuing without an image")
+		h.log.DebugContext(c, "No file uploaded, continuing without an image")
 	}
 
 	req.ImageUrl = url
@@ -135,8 +129,8 @@ func (h *Handler) UpdateCategory(c *gin.Context) {
 
 	res, err := h.ProductClient.UpdateCategory(c, req)
 	if err != nil {
-		h.log.Error("Error updating category", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error updating category", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -153,14 +147,14 @@ func (h *Handler) UpdateCategory(c *gin.Context) {
 // @Param branch_id header string true "Branch ID"
 // @Param id path string true "Category ID"
 // @Success 200 {object} products.Category "Category details"
-// @Failure 400 {object} products.Error "Invalid input or bad request"
-// @Failure 500 {object} products.Error "Internal server error"
+// @Failure 400 {object} entity.Error "Invalid input or bad request"
+// @Failure 500 {object} entity.Error "Internal server error"
 // @Router /products/category/{id} [get]
 func (h *Handler) GetCategory(c *gin.Context) {
-	branchID := c.GetHeader("branch_id")
+	branchID := c.GetString(middleware.BranchKey)
 	if branchID == "" {
-		h.log.Error("Branch ID is missing in the header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		h.log.ErrorContext(c, "Branch ID is missing in the header")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -173,8 +167,8 @@ func (h *Handler) GetCategory(c *gin.Context) {
 
 	res, err := h.ProductClient.GetCategory(c, req)
 	if err != nil {
-		h.log.Error("Error fetching category", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching category", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -194,14 +188,14 @@ func (h *Handler) GetCategory(c *gin.Context) {
 // @Param limit query string false "Limit"
 // @Param page query string false "Page"
 // @Success 200 {object} products.CategoryList "List of categories"
-// @Failure 400 {object} products.Error "Bad request due to invalid query parameters"
-// @Failure 500 {object} products.Error "Internal server error"
+// @Failure 400 {object} entity.Error "Bad request due to invalid query parameters"
+// @Failure 500 {object} entity.Error "Internal server error"
 // @Router /products/category [get]
 func (h *Handler) GetListCategory(c *gin.Context) {
-	branchID := c.GetHeader("branch_id")
+	branchID := c.GetString(middleware.BranchKey)
 	if branchID == "" {
-		h.log.Error("Branch ID is missing in the header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		h.log.ErrorContext(c, "Branch ID is missing in the header")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -218,7 +212,7 @@ func (h *Handler) GetListCategory(c *gin.Context) {
 		page = 0
 	}
 
-	log.Println(limit, page)
+	h.log.DebugContext(c, "Listing categories", "limit", limit, "page", page)
 
 	req.Limit = limit
 	req.Page = page
@@ -227,8 +221,8 @@ func (h *Handler) GetListCategory(c *gin.Context) {
 
 	res, err := h.ProductClient.GetListCategory(c.Request.Context(), &req)
 	if err != nil {
-		h.log.Error("Failed to retrieve category list", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error: " + err.Error()})
+		h.log.ErrorContext(c, "Failed to retrieve category list", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -245,14 +239,14 @@ func (h *Handler) GetListCategory(c *gin.Context) {
 // @Param branch_id header string true "Branch ID"
 // @Param id path string true "Category ID"
 // @Success 200 {object} products.Message "Category successfully deleted"
-// @Failure 400 {object} products.Error "Invalid input or bad request"
-// @Failure 500 {object} products.Error "Internal server error"
+// @Failure 400 {object} entity.Error "Invalid input or bad request"
+// @Failure 500 {object} entity.Error "Internal server error"
 // @Router /products/category/{id} [delete]
 func (h *Handler) DeleteCategory(c *gin.Context) {
-	branchID := c.GetHeader("branch_id")
+	branchID := c.GetString(middleware.BranchKey)
 	if branchID == "" {
-		h.log.Error("Branch ID is missing in the header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		h.log.ErrorContext(c, "Branch ID is missing in the header")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -265,8 +259,8 @@ func (h *Handler) DeleteCategory(c *gin.Context) {
 
 	res, err := h.ProductClient.DeleteCategory(c, req)
 	if err != nil {
-		h.log.Error("Error deleting category", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error deleting category", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
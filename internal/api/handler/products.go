@@ -3,10 +3,10 @@ package handler
 import (
 	"bytes"
 	"fmt"
+	"gateway/internal/api/middleware"
+	"gateway/internal/api/response"
 	"gateway/internal/entity"
 	"gateway/internal/generated/products"
-	"gateway/internal/minio"
-	"log"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -33,37 +33,35 @@ import (
 // @Param quantity formData int64 false "Standard price of the product"
 // @Param branch_id header string true "Branch ID"
 // @Success 201 {object} products.Product "Product successfully created"
-// @Failure 400 {object} products.Error "Invalid input or bad request"
-// @Failure 500 {object} products.Error "Internal server error"
+// @Failure 400 {object} entity.Error "Invalid input or bad request"
+// @Failure 500 {object} entity.Error "Internal server error"
 // @Router /products [post]
 func (h *Handler) CreateProduct(c *gin.Context) {
-	branchID := c.GetHeader("branch_id")
+	branchID := c.GetString(middleware.BranchKey)
 	if branchID == "" {
-		h.log.Error("Branch ID is missing in the header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		h.log.ErrorContext(c, "Branch ID is missing in the header")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	var req entity.CreateProductRequest
 	if err := c.ShouldBind(&req); err != nil {
-		h.log.Error("bind json err", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request", "error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
 	var url string
 	file, err := c.FormFile("file")
 	if err == nil {
-		url, err = minio.UploadMedia(file)
+		url, err = h.Media.UploadMedia(c, file)
 		if err != nil {
-			log.Println("Error occurred while uploading file")
-			h.log.Error("Error occurred while uploading file:", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			h.log.ErrorContext(c, "Error occurred while uploading file", "error", err.Error())
+			response.Error(c, http.StatusBadGateway, "Failed to upload file")
 			return
 		}
 	} else {
 		url = "no image"
-		log.Println("No file uploaded, continuing without an image")
+		h.log.DebugContext(c, "No file uploaded, continuing without an image")
 	}
 
 	res, err := h.ProductClient.CreateProduct(c, &products.CreateProductRequest{
@@ -79,8 +77,8 @@ func (h *Handler) CreateProduct(c *gin.Context) {
 		BranchId:      branchID, // Pass branch ID
 	})
 	if err != nil {
-		h.log.Error("Error creating product", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error creating product", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -104,37 +102,36 @@ func (h *Handler) CreateProduct(c *gin.Context) {
 // @Param standard_price formData float64 true "Standard price"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.Product
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /products/{id} [put]
 func (h *Handler) UpdateProduct(c *gin.Context) {
 	id := c.Param("id")
 
-	branchID := c.GetHeader("branch_id")
+	branchID := c.GetString(middleware.BranchKey)
 	if branchID == "" {
-		h.log.Error("Branch ID is missing in the header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		h.log.ErrorContext(c, "Branch ID is missing in the header")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	var form UpdateProductForm
 	if err := c.ShouldBind(&form); err != nil {
-		h.log.Error("Error binding form data", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
 	var url string
 	file, err := c.FormFile("file")
 	if err == nil {
-		url, err = minio.UploadMedia(file)
+		url, err = h.Media.UploadMedia(c, file)
 		if err != nil {
-			h.log.Error("Error occurred while uploading file", "error", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			h.log.ErrorContext(c, "Error occurred while uploading file", "error", err.Error())
+			response.Error(c, http.StatusBadGateway, "Failed to upload file")
 			return
 		}
 	} else {
-		h.log.Info("No file uploaded, continuing without an image")
+		h.log.InfoContext(c, "No file uploaded, continuing without an image")
 	}
 
 	req := products.UpdateProductRequest{
@@ -152,8 +149,8 @@ func (h *Handler) UpdateProduct(c *gin.Context) {
 
 	res, err := h.ProductClient.UpdateProduct(c, &req)
 	if err != nil {
-		h.log.Error("Error updating product", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error updating product", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -179,16 +176,16 @@ type UpdateProductForm struct {
 // @Param id path string true "Product ID"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.Message
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /products/{id} [delete]
 func (h *Handler) DeleteProduct(c *gin.Context) {
 	id := c.Param("id")
 
-	branchID := c.GetHeader("branch_id")
+	branchID := c.GetString(middleware.BranchKey)
 	if branchID == "" {
-		h.log.Error("Branch ID is missing in the header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		h.log.ErrorContext(c, "Branch ID is missing in the header")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -196,8 +193,8 @@ func (h *Handler) DeleteProduct(c *gin.Context) {
 
 	res, err := h.ProductClient.DeleteProduct(c, req)
 	if err != nil {
-		h.log.Error("Error deleting product", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error deleting product", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -214,16 +211,16 @@ func (h *Handler) DeleteProduct(c *gin.Context) {
 // @Param id path string true "Product ID"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.Product
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /products/{id} [get]
 func (h *Handler) GetProduct(c *gin.Context) {
 	id := c.Param("id")
 
-	branchID := c.GetHeader("branch_id")
+	branchID := c.GetString(middleware.BranchKey)
 	if branchID == "" {
-		h.log.Error("Branch ID is missing in the header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		h.log.ErrorContext(c, "Branch ID is missing in the header")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -231,8 +228,8 @@ func (h *Handler) GetProduct(c *gin.Context) {
 
 	res, err := h.ProductClient.GetProduct(c, req)
 	if err != nil {
-		h.log.Error("Error fetching product", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching product", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -254,32 +251,26 @@ func (h *Handler) GetProduct(c *gin.Context) {
 // @Param limit query int false "Number of products to return (default 10)"
 // @Param page query int false "Offset for pagination (default 1)"
 // @Success 200 {array} products.Product
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /products [get]
 func (h *Handler) GetProductList(c *gin.Context) {
-	// Логируем все заголовки для отладки
-	for k, v := range c.Request.Header {
-		log.Printf("Header: %s = %v", k, v)
-	}
-
-	branchID := c.GetHeader("branch_id")
+	branchID := c.GetString(middleware.BranchKey)
 	if branchID == "" {
-		h.log.Error("Branch ID is missing in the header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		h.log.ErrorContext(c, "Branch ID is missing in the header")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	// Преобразуем параметры Limit и Page в int64
 	var filter entity.ProductFilter
 	if err := c.ShouldBindQuery(&filter); err != nil {
-		h.log.Error("Error parsing ProductFilter", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
 	// Выводим параметры фильтра в лог
-	log.Println("Limit:", filter.Limit, "Page:", filter.Page, "TotalCount:", filter.TotalCount)
+	h.log.DebugContext(c, "Listing products", "limit", filter.Limit, "page", filter.Page, "total_count", filter.TotalCount)
 
 	// Call the ProductClient to retrieve the product list
 	res, err := h.ProductClient.GetProductList(c, &products.ProductFilter{
@@ -294,8 +285,8 @@ func (h *Handler) GetProductList(c *gin.Context) {
 		BranchId:   branchID,
 	})
 	if err != nil {
-		h.log.Error("Error retrieving product list", "filter", filter, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve product list: " + err.Error()})
+		h.log.ErrorContext(c, "Error retrieving product list", "filter", filter, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -321,8 +312,8 @@ func (h *Handler) GetProductList(c *gin.Context) {
 func (h *Handler) UploadAndProcessExcel(c *gin.Context) {
 	file, err := c.FormFile("file")
 	if err != nil {
-		h.log.Error("Error retrieving file", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		h.log.ErrorContext(c, "Error retrieving file", "error", err)
+		response.Error(c, http.StatusBadRequest, "File is required")
 		return
 	}
 
@@ -331,49 +322,49 @@ func (h *Handler) UploadAndProcessExcel(c *gin.Context) {
 	}
 
 	if err := c.ShouldBind(&sheet); err != nil {
-		h.log.Error("Error parsing form data", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Sheet name is required"})
+		h.log.ErrorContext(c, "Error parsing form data", "error", err)
+		response.Error(c, http.StatusBadRequest, "Sheet name is required")
 		return
 	}
 
 	fileContent, err := file.Open()
 	if err != nil {
-		h.log.Error("Error opening file", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to open file"})
+		h.log.ErrorContext(c, "Error opening file", "error", err)
+		response.Error(c, http.StatusInternalServerError, "Unable to open file")
 		return
 	}
 	defer fileContent.Close()
 
 	buffer := bytes.NewBuffer(nil)
 	if _, err := io.Copy(buffer, fileContent); err != nil {
-		h.log.Error("Error reading file content", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reading file"})
+		h.log.ErrorContext(c, "Error reading file content", "error", err)
+		response.Error(c, http.StatusInternalServerError, "Error reading file")
 		return
 	}
 
 	excelFile, err := excelize.OpenReader(buffer)
 	if err != nil {
-		h.log.Error("Error parsing Excel file", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error parsing Excel file", "error", err)
+		response.Error(c, http.StatusBadRequest, "File is not a valid Excel document")
 		return
 	}
 
 	rows, err := excelFile.GetRows(sheet.SheetName)
 	if err != nil {
-		h.log.Error("Error reading sheet", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error reading sheet", "error", err)
+		response.Error(c, http.StatusBadRequest, "Sheet not found in the uploaded file")
 		return
 	}
 
 	categoryId := c.Param("category_id")
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	erroredRows := make([]string, 0)
-	var createdProducts []products.Product
+	var createdProducts []*products.Product
 
 	for i, row := range rows {
 		if i == 0 || len(row) < 4 { // Skip header row and ensure sufficient data
@@ -395,11 +386,11 @@ func (h *Handler) UploadAndProcessExcel(c *gin.Context) {
 
 		product, err := h.ProductClient.CreateProduct(c, req)
 		if err != nil {
-			h.log.Error("Error creating product", "row", i+1, "error", err)
+			h.log.ErrorContext(c, "Error creating product", "row", i+1, "error", err)
 			erroredRows = append(erroredRows, fmt.Sprintf("%d", i+1))
 			continue
 		}
-		createdProducts = append(createdProducts, *product)
+		createdProducts = append(createdProducts, product)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -421,35 +412,41 @@ func (h *Handler) UploadAndProcessExcel(c *gin.Context) {
 // @Param category_id path string true "Category ID"
 // @Param branch_id header string true "Branch ID"
 // @Success 201 {object} products.BulkCreateResponse "Bulk products successfully created"
-// @Failure 400 {object} map[string]string "Invalid input or bad request"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} entity.Error "Invalid input or bad request"
+// @Failure 500 {object} entity.Error "Internal server error"
 // @Router /products/bulk/{category_id} [post]
 func (h *Handler) CreateBulkProducts(c *gin.Context) {
-	var req products.CreateBulkProductsRequest
+	var path entity.CategoryPath
+	if err := c.ShouldBindUri(&path); err != nil {
+		response.Invalid(c, err)
+		return
+	}
 
-	// Bind the request body to the struct
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+	var body entity.CreateBulkProductsRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Invalid(c, err)
 		return
 	}
 
 	// Extract necessary values from the context
+	req := body.Proto()
 	req.CreatedBy = c.MustGet("id").(string)
 	req.CompanyId = c.MustGet("company_id").(string)
-	req.CategoryId = c.Param("category_id")
+	req.CategoryId = path.CategoryId
 
 	// Get branch_id from the header
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 	req.BranchId = branchId
 
 	// Call the gRPC service
-	resp, err := h.ProductClient.CreateBulkProducts(c, &req)
+	resp, err := h.ProductClient.CreateBulkProducts(c, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create products: " + err.Error()})
+		h.log.ErrorContext(c, "Failed to create products", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -467,17 +464,17 @@ func (h *Handler) CreateBulkProducts(c *gin.Context) {
 // @Param        currency  path     string true "Currency type (allowed values: 'uzs', 'usd')"
 // @Security     ApiKeyAuth
 // @Success      200  {object}  products.GetProductsDashboardRes "Dashboard data retrieved successfully"
-// @Failure      400  {object}  products.Error             "Invalid request parameters"
-// @Failure      500  {object}  products.Error             "Internal server error"
+// @Failure      400  {object}  entity.Error             "Invalid request parameters"
+// @Failure      500  {object}  entity.Error             "Internal server error"
 // @Router       /products/dashboard/{currency} [get]
 func (h *Handler) GetProductsDashboard(c *gin.Context) {
 
 	var req products.GetProductsDashboardReq
 
-	req.BranchId = c.GetHeader("branch_id")
+	req.BranchId = c.GetString(middleware.BranchKey)
 	if req.BranchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
-		h.log.Error("Branch ID is required")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
+		h.log.ErrorContext(c, "Branch ID is required")
 		return
 	}
 
@@ -486,8 +483,8 @@ func (h *Handler) GetProductsDashboard(c *gin.Context) {
 
 	res, err := h.ProductClient.GetProductDashboard(c, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get products dashboard: " + err.Error()})
-		h.log.Error("Failed to get products dashboard", "error", err.Error())
+		h.log.ErrorContext(c, "Failed to get products dashboard", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"gateway/internal/api/middleware"
+	"gateway/internal/api/response"
+	"gateway/internal/entity"
 	"gateway/internal/generated/products"
 	"gateway/internal/generated/user"
 	"github.com/gin-gonic/gin"
-	"log"
 	"net/http"
 	"strconv"
 )
@@ -19,33 +21,34 @@ import (
 // @Param Purchase body entity.Purchase true "Purchase data"
 // @Param branch_id header string true "Branch ID"
 // @Success 201 {object} products.PurchaseResponse
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /purchases [post]
 func (h *Handler) CreatePurchase(c *gin.Context) {
-	var req products.PurchaseRequest
+	var body entity.Purchase
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Error parsing CreatePurchase request body", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "Error parsing CreatePurchase request body", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	req := body.Proto()
 
 	req.PurchasedBy = c.MustGet("id").(string)
 	req.CompanyId = c.MustGet("company_id").(string)
 
 	// Получение branch_id из заголовка
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 	req.BranchId = branchId
 
-	res, err := h.ProductClient.CreatePurchase(c, &req)
+	res, err := h.ProductClient.CreatePurchase(c, req)
 	if err != nil {
-		h.log.Error("Error creating purchase", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error creating purchase", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -62,14 +65,14 @@ func (h *Handler) CreatePurchase(c *gin.Context) {
 // @Param id path string true "Purchase ID"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.PurchaseResponse
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /purchases/{id} [get]
 func (h *Handler) GetPurchase(c *gin.Context) {
 	id := c.Param("id")
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -81,8 +84,8 @@ func (h *Handler) GetPurchase(c *gin.Context) {
 
 	res, err := h.ProductClient.GetPurchase(c, req)
 	if err != nil {
-		h.log.Error("Error fetching purchase", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching purchase", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -99,8 +102,8 @@ func (h *Handler) GetPurchase(c *gin.Context) {
 // @Param filter query entity.FilterPurchase false "Filter parameters"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.PurchaseList
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /purchases [get]
 func (h *Handler) GetListPurchase(c *gin.Context) {
 	var filter products.FilterPurchase
@@ -111,7 +114,7 @@ func (h *Handler) GetListPurchase(c *gin.Context) {
 	purchasedBy := c.Query("purchased_by")
 	companyId := c.MustGet("company_id").(string)
 	createdAt := c.Query("created_at")
-	branchId := c.GetHeader("branch_id") // Получаем из заголовков
+	branchId := c.GetString(middleware.BranchKey) // Получаем из заголовков
 	description := c.Query("description")
 	totalCost := c.Query("total_cost")
 
@@ -127,8 +130,8 @@ func (h *Handler) GetListPurchase(c *gin.Context) {
 		var err error
 		limit, err = strconv.ParseInt(limitStr, 10, 64)
 		if err != nil {
-			h.log.Error("Error parsing limit", "error", err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+			h.log.ErrorContext(c, "Error parsing limit", "error", err.Error())
+			response.Error(c, http.StatusBadRequest, "Invalid limit parameter")
 			return
 		}
 	}
@@ -137,8 +140,8 @@ func (h *Handler) GetListPurchase(c *gin.Context) {
 		var err error
 		page, err = strconv.ParseInt(pageStr, 10, 64)
 		if err != nil {
-			h.log.Error("Error parsing page", "error", err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page parameter"})
+			h.log.ErrorContext(c, "Error parsing page", "error", err.Error())
+			response.Error(c, http.StatusBadRequest, "Invalid page parameter")
 			return
 		}
 	}
@@ -148,18 +151,19 @@ func (h *Handler) GetListPurchase(c *gin.Context) {
 		var err error
 		totalCostFloat, err = strconv.ParseFloat(totalCost, 64)
 		if err != nil {
-			h.log.Error("Error parsing total cost", "error", err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid total cost parameter"})
+			h.log.ErrorContext(c, "Error parsing total cost", "error", err.Error())
+			response.Error(c, http.StatusBadRequest, "Invalid total cost parameter")
 			return
 		}
 	}
 
 	// Логируем параметры запроса для отладки
-	log.Println("ProductId:", productName, "SupplierId:", supplierId, "PurchasedBy:", purchasedBy, "CreatedAt:", createdAt, "BranchId:", branchId, "Limit:", limit, "Page:", page, "ProductName", productName)
+	h.log.DebugContext(c, "Listing purchases", "product_name", productName, "supplier_id", supplierId, "purchased_by", purchasedBy,
+		"created_at", createdAt, "branch_id", branchId, "limit", limit, "page", page)
 
 	// Проверяем наличие branchId
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -178,8 +182,8 @@ func (h *Handler) GetListPurchase(c *gin.Context) {
 
 	res, err := h.ProductClient.GetListPurchase(c, &filter)
 	if err != nil {
-		h.log.Error("Error retrieving purchase list", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error retrieving purchase list", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -188,14 +192,14 @@ func (h *Handler) GetListPurchase(c *gin.Context) {
 		if err == nil {
 			res.Purchases[i].SupplierName = clientRes.FullName
 		} else {
-			h.log.Error("Error fetching supplier details", "supplier_id", purchase.SupplierId, "error", err.Error())
+			h.log.ErrorContext(c, "Error fetching supplier details", "supplier_id", purchase.SupplierId, "error", err.Error())
 		}
 
 		purchaserRes, err := h.UserClient.GetClient(c, &user.UserIDRequest{Id: purchase.PurchasedBy, CompanyId: filter.CompanyId})
 		if err == nil {
 			res.Purchases[i].PurchaserPhoneNumber = purchaserRes.Phone
 		} else {
-			h.log.Error("Error fetching purchaser details", "purchased_by", purchase.PurchasedBy, "error", err.Error())
+			h.log.ErrorContext(c, "Error fetching purchaser details", "purchased_by", purchase.PurchasedBy, "error", err.Error())
 		}
 	}
 
@@ -213,33 +217,33 @@ func (h *Handler) GetListPurchase(c *gin.Context) {
 // @Param Purchase body entity.PurchaseUpdate true "Updated purchase data"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.PurchaseResponse
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /purchases/{id} [put]
 func (h *Handler) UpdatePurchase(c *gin.Context) {
-	id := c.Param("id")
-	var req products.PurchaseUpdate
-	req.Id = id
+	var body entity.PurchaseUpdate
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Error parsing UpdatePurchase request body", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "Error parsing UpdatePurchase request body", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	req := body.Proto()
+	req.Id = c.Param("id")
 
 	req.CompanyId = c.MustGet("company_id").(string)
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 	req.BranchId = branchId
 
-	res, err := h.ProductClient.UpdatePurchase(c, &req)
+	res, err := h.ProductClient.UpdatePurchase(c, req)
 	if err != nil {
-		h.log.Error("Error updating purchase", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error updating purchase", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -256,15 +260,15 @@ func (h *Handler) UpdatePurchase(c *gin.Context) {
 // @Param id path string true "Purchase ID"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.Message
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /purchases/{id} [delete]
 func (h *Handler) DeletePurchase(c *gin.Context) {
 	id := c.Param("id")
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -276,8 +280,8 @@ func (h *Handler) DeletePurchase(c *gin.Context) {
 
 	res, err := h.ProductClient.DeletePurchase(c, req)
 	if err != nil {
-		h.log.Error("Error deleting purchase", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error deleting purchase", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"gateway/internal/api/response"
+	"gateway/internal/entity"
+	"gateway/internal/generated/company"
+	"gateway/internal/rbac"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// GetPermissionCatalog godoc
+// @Summary Permission catalog
+// @Description List the routes an owner may grant to company roles
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} rbac.Permission
+// @Failure 500 {object} entity.Error
+// @Router /companies/permissions [get]
+func (h *Handler) GetPermissionCatalog(c *gin.Context) {
+	catalog, err := h.Roles.Catalog()
+	if err != nil {
+		h.log.ErrorContext(c, "Error building permission catalog", "error", err.Error())
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, catalog)
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List the roles defined by the company
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} rbac.Role
+// @Failure 500 {object} entity.Error
+// @Router /companies/roles [get]
+func (h *Handler) ListRoles(c *gin.Context) {
+	roles, err := h.Roles.Roles(c.MustGet("company_id").(string))
+	if err != nil {
+		h.log.ErrorContext(c, "Error listing roles", "error", err.Error())
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// CreateRole godoc
+// @Summary Create role
+// @Description Create a company role from catalog permissions, optionally inheriting from worker or another company role
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Role body rbac.Role true "Role"
+// @Success 201 {object} rbac.Role
+// @Failure 400 {object} entity.Error
+// @Failure 409 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /companies/roles [post]
+func (h *Handler) CreateRole(c *gin.Context) {
+	var req rbac.Role
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Invalid(c, err)
+		return
+	}
+
+	if err := h.Roles.CreateRole(c.MustGet("company_id").(string), req); err != nil {
+		h.roleError(c, "Error creating role", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, req)
+}
+
+// UpdateRole godoc
+// @Summary Update role
+// @Description Replace the permissions and parents of a company role
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param role path string true "Role name"
+// @Param Role body rbac.Role true "Role"
+// @Success 200 {object} rbac.Role
+// @Failure 400 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /companies/roles/{role} [put]
+func (h *Handler) UpdateRole(c *gin.Context) {
+	var req rbac.Role
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Invalid(c, err)
+		return
+	}
+	req.Name = c.Param("role")
+
+	if err := h.Roles.UpdateRole(c.MustGet("company_id").(string), req); err != nil {
+		h.roleError(c, "Error updating role", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// DeleteRole godoc
+// @Summary Delete role
+// @Description Delete a company role and unassign it from every user
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param role path string true "Role name"
+// @Success 200 {object} company.Message
+// @Failure 404 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /companies/roles/{role} [delete]
+func (h *Handler) DeleteRole(c *gin.Context) {
+	if err := h.Roles.DeleteRole(c.MustGet("company_id").(string), c.Param("role")); err != nil {
+		h.roleError(c, "Error deleting role", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &company.Message{Message: "role deleted"})
+}
+
+// GetUserRoles godoc
+// @Summary Get user roles
+// @Description Get the company roles assigned to a user. A user without company roles is authorized by the role in their token.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id path string true "User ID"
+// @Success 200 {object} entity.UserRoles
+// @Router /companies/users/{user_id}/roles [get]
+func (h *Handler) GetUserRoles(c *gin.Context) {
+	userID := c.Param("user_id")
+	roles := h.Roles.UserRoles(c.MustGet("company_id").(string), userID)
+
+	c.JSON(http.StatusOK, entity.UserRoles{UserId: userID, Roles: roles})
+}
+
+// SetUserRoles godoc
+// @Summary Set user roles
+// @Description Replace the company roles of a user of the company. An empty list restores the role in their token.
+// @Description The user ID cannot name a built-in role (admin, owner, worker) or a company role.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id path string true "User ID"
+// @Param Roles body entity.UserRolesReq true "Role names"
+// @Success 200 {object} entity.UserRoles
+// @Failure 400 {object} entity.Error
+// @Failure 404 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /companies/users/{user_id}/roles [put]
+func (h *Handler) SetUserRoles(c *gin.Context) {
+	var req entity.UserRolesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Invalid(c, err)
+		return
+	}
+
+	userID := c.Param("user_id")
+	if userID == c.GetString("id") {
+		response.Error(c, http.StatusBadRequest, "you cannot change your own roles")
+		return
+	}
+
+	companyID := c.MustGet("company_id").(string)
+	member, err := h.companyUser(c.Request.Context(), companyID, userID)
+	if err != nil {
+		h.log.ErrorContext(c, "Error listing company users", "error", err.Error())
+		response.FromGRPC(c, err)
+		return
+	}
+	if !member {
+		response.Error(c, http.StatusNotFound, "user does not belong to the company")
+		return
+	}
+
+	if err := h.Roles.SetUserRoles(companyID, userID, req.Roles); err != nil {
+		h.roleError(c, "Error assigning roles", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.UserRoles{UserId: userID, Roles: h.Roles.UserRoles(companyID, userID)})
+}
+
+// companyUsersPageSize is the page size used to walk the users of a company.
+const companyUsersPageSize = 100
+
+// companyUser reports whether userID is a user of the company.
+func (h *Handler) companyUser(ctx context.Context, companyID, userID string) (bool, error) {
+	seen := 0
+	for page := int32(1); ; page++ {
+		res, err := h.CompanyClient.ListCompanyUsers(ctx, &company.ListCompanyUsersRequest{
+			CompanyId: companyID,
+			Limit:     companyUsersPageSize,
+			Page:      page,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		for _, u := range res.Users {
+			if u.UserId == userID {
+				return true, nil
+			}
+		}
+
+		seen += len(res.Users)
+		if len(res.Users) < companyUsersPageSize || int64(seen) >= res.TotalCount {
+			return false, nil
+		}
+	}
+}
+
+// roleError maps errors of the role manager to responses.
+func (h *Handler) roleError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, rbac.ErrRoleNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, rbac.ErrRoleExists):
+		response.Error(c, http.StatusConflict, err.Error())
+	case errors.Is(err, rbac.ErrInvalidRoleName), errors.Is(err, rbac.ErrReservedRole),
+		errors.Is(err, rbac.ErrUnknownPermission), errors.Is(err, rbac.ErrInvalidParent),
+		errors.Is(err, rbac.ErrEmptyRole), errors.Is(err, rbac.ErrReservedSubject):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		h.log.ErrorContext(c, msg, "error", err.Error())
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+	}
+}
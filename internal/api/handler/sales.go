@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"gateway/internal/api/middleware"
+	"gateway/internal/api/response"
 	"gateway/internal/entity"
 	"gateway/internal/generated/debts"
 	"gateway/internal/generated/products"
 	"gateway/internal/generated/user"
+	"gateway/internal/rbac"
 	"github.com/gin-gonic/gin"
-	"log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"net/http"
 	"strconv"
 )
@@ -20,23 +24,24 @@ import (
 // @Security ApiKeyAuth
 // @Param Sale body entity.Sale true "Sale data"
 // @Success 200 {object} products.SaleResponse
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /sales/calculate [post]
 func (h *Handler) CalculateTotalSales(c *gin.Context) {
-	var req products.SaleRequest
+	var body entity.Sale
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Error parsing CalculateTotalSales request body", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "Error parsing CalculateTotalSales request body", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	req := body.Proto()
 	req.SoldBy = c.MustGet("id").(string)
 	req.CompanyId = c.MustGet("company_id").(string)
-	res, err := h.ProductClient.CalculateTotalSales(c, &req)
+	res, err := h.ProductClient.CalculateTotalSales(c, req)
 	if err != nil {
-		h.log.Error("Error calculating total sales", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error calculating total sales", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -53,32 +58,33 @@ func (h *Handler) CalculateTotalSales(c *gin.Context) {
 // @Param branch_id header string true "Branch ID"
 // @Param Sale body entity.Sale true "Sale data"
 // @Success 201 {object} products.SaleResponse
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /sales [post]
 func (h *Handler) CreateSales(c *gin.Context) {
-	var req products.SaleRequest
+	var body entity.Sale
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Error parsing CreateSales request body", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "Error parsing CreateSales request body", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	req := body.Proto()
 
 	req.SoldBy, _ = c.MustGet("id").(string)
 	req.CompanyId, _ = c.MustGet("company_id").(string)
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		h.log.Error("Missing branch_id in header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		h.log.ErrorContext(c, "Missing branch_id in header")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 	req.BranchId = branchId
 
-	if len(req.ClientId) < 16 {
+	if req.ClientId == "" {
 		if req.ClientName == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Client ID or Client Name is required"})
+			response.Error(c, http.StatusBadRequest, "Client ID or Client Name is required")
 			return
 		}
 
@@ -93,23 +99,23 @@ func (h *Handler) CreateSales(c *gin.Context) {
 
 		client, err := h.UserClient.CreateClient(c, &clientReq)
 		if err != nil {
-			h.log.Error("Error creating client for sale", "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			h.log.ErrorContext(c, "Error creating client for sale", "error", err.Error())
+			response.FromGRPC(c, err)
 			return
 		}
 
 		req.ClientId = client.Id
 		if req.ClientId == "" {
-			h.log.Error("Created client has no ID")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			h.log.ErrorContext(c, "Created client has no ID")
+			response.Error(c, http.StatusInternalServerError, "internal server error")
 			return
 		}
 	}
 
-	res, err := h.ProductClient.CreateSales(c, &req)
+	res, err := h.ProductClient.CreateSales(c, req)
 	if err != nil {
-		h.log.Error("Error creating sale", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error creating sale", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -125,14 +131,14 @@ func (h *Handler) CreateSales(c *gin.Context) {
 
 		debtRes, err := h.DebtClient.CreateDebts(c, &debReq)
 		if err != nil {
-			h.log.Error("Error creating debt", "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			h.log.ErrorContext(c, "Error creating debt", "error", err.Error())
+			response.FromGRPC(c, err)
 			return
 		}
 
 		if debtRes.Id == "" {
-			h.log.Error("Created debt has no ID")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			h.log.ErrorContext(c, "Created debt has no ID")
+			response.Error(c, http.StatusInternalServerError, "internal server error")
 			return
 		}
 
@@ -144,8 +150,8 @@ func (h *Handler) CreateSales(c *gin.Context) {
 			}
 
 			if _, err = h.DebtClient.PayDebts(c, &reqPay); err != nil {
-				h.log.Error("Error processing debt payment", "error", err.Error())
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				h.log.ErrorContext(c, "Error processing debt payment", "error", err.Error())
+				response.FromGRPC(c, err)
 				return
 			}
 		}
@@ -156,7 +162,7 @@ func (h *Handler) CreateSales(c *gin.Context) {
 
 // UpdateSales godoc
 // @Summary Update an existing sale
-// @Description Update the details of an existing sale by ID
+// @Description Update the details of an existing sale by ID. Workers may only update their own sales within SALE_EDIT_WINDOW minutes of creating them.
 // @Tags Sales
 // @Accept json
 // @Produce json
@@ -165,39 +171,66 @@ func (h *Handler) CreateSales(c *gin.Context) {
 // @Param Sale body entity.SaleUpdate true "Updated sale data"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.SaleResponse
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 403 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /sales/{id} [put]
 func (h *Handler) UpdateSales(c *gin.Context) {
-	var req products.SaleUpdate
-	id := c.Param("id")
-	req.Id = id
+	var body entity.SaleUpdate
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Error parsing UpdateSales request body", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "Error parsing UpdateSales request body", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	req := body.Proto()
+	req.Id = c.Param("id")
 
 	req.CompanyId = c.MustGet("company_id").(string)
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 	req.BranchId = branchId
 
-	res, err := h.ProductClient.UpdateSales(c, &req)
+	res, err := h.ProductClient.UpdateSales(c, req)
 	if err != nil {
-		h.log.Error("Error updating sale", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error updating sale", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, res)
 }
 
+// SaleRecord loads the sale addressed by the request for the ownership rules.
+func (h *Handler) SaleRecord(c *gin.Context) (rbac.Record, error) {
+	branchId := c.GetString(middleware.BranchKey)
+	if branchId == "" {
+		return rbac.Record{}, status.Error(codes.InvalidArgument, "Branch ID is required in the header")
+	}
+
+	sale, err := h.ProductClient.GetSales(c, &products.SaleID{
+		Id:        c.Param("id"),
+		CompanyId: c.MustGet("company_id").(string),
+		BranchId:  branchId,
+	})
+	if err != nil {
+		h.log.ErrorContext(c, "Error fetching sale for ownership check", "error", err.Error())
+		return rbac.Record{}, err
+	}
+
+	return rbac.Record{
+		Type:       "sale",
+		Id:         sale.Id,
+		Owner:      sale.SoldBy,
+		CompanyId:  sale.CompanyId,
+		AgeMinutes: h.Ownership.Age(sale.CreatedAt),
+	}, nil
+}
+
 // GetSales godoc
 // @Summary Get a sale
 // @Description Retrieve a sale by ID
@@ -208,16 +241,16 @@ func (h *Handler) UpdateSales(c *gin.Context) {
 // @Param id path string true "Sale ID"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.SaleResponse
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /sales/{id} [get]
 func (h *Handler) GetSales(c *gin.Context) {
 	id := c.Param("id")
 
 	// Получаем branch_id из заголовков
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -229,8 +262,8 @@ func (h *Handler) GetSales(c *gin.Context) {
 
 	res, err := h.ProductClient.GetSales(c, req)
 	if err != nil {
-		h.log.Error("Error fetching sale", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching sale", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -253,39 +286,44 @@ func (h *Handler) GetSales(c *gin.Context) {
 // @Param sold_by query string false "Sold by user ID to filter sales"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.SaleList
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /sales [get]
 func (h *Handler) GetListSales(c *gin.Context) {
 
 	productName := c.Query("product_name")
 	limitStr := c.Query("limit") // Значение по умолчанию - 10
 	pageStr := c.Query("page")   // Значение по умолчанию - 1
-	startDate := c.Query("start_date")
-	endDate := c.Query("end_date")
 	clientId := c.Query("client_id")
 	soldBy := c.Query("sold_by")
-	branchId := c.GetHeader("branch_id") // Получаем из заголовков
+	branchId := c.GetString(middleware.BranchKey) // Получаем из заголовков
 
 	// Преобразуем limit и page в int64
 	limit, err := strconv.ParseInt(limitStr, 10, 64)
 	if err != nil {
-		h.log.Error("Error parsing limit", "error", err.Error())
+		h.log.ErrorContext(c, "Error parsing limit", "error", err.Error())
 		limit = 0
 	}
 
 	page, err := strconv.ParseInt(pageStr, 10, 64)
 	if err != nil {
-		h.log.Error("Error parsing page", "error", err.Error())
+		h.log.ErrorContext(c, "Error parsing page", "error", err.Error())
 		page = 0
 	}
 
+	var dates entity.DateFilter
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		h.log.ErrorContext(c, "Invalid GetListSales date filter", "error", err.Error())
+		response.Invalid(c, err)
+		return
+	}
+
 	// Логируем переданные параметры для отладки
-	log.Println("Limit:", limit, "Page:", page, "StartDate:", startDate, "EndDate:", endDate, "ProductName", productName)
+	h.log.DebugContext(c, "Listing sales", "limit", limit, "page", page, "start_date", dates.StartDate, "end_date", dates.EndDate, "product_name", productName)
 
 	// Проверяем, если branchId пустой, то возвращаем ошибку
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -301,15 +339,15 @@ func (h *Handler) GetListSales(c *gin.Context) {
 		CompanyId:   companyId,
 		SoldBy:      soldBy,
 		ClientId:    clientId,
-		StartDate:   startDate,
-		EndDate:     endDate,
+		StartDate:   dates.StartDate,
+		EndDate:     dates.EndDate,
 	}
 
 	// Получаем список продаж с учетом фильтрации
 	res, err := h.ProductClient.GetListSales(c, &filter)
 	if err != nil {
-		h.log.Error("Error retrieving sales list", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error retrieving sales list", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -320,14 +358,14 @@ func (h *Handler) GetListSales(c *gin.Context) {
 			res.Sales[i].ClientName = clientRes.FullName
 			res.Sales[i].ClientPhoneNumber = clientRes.Phone
 		} else {
-			h.log.Error("Error fetching customer details", "customer_id", sale.ClientId, "error", err.Error())
+			h.log.ErrorContext(c, "Error fetching customer details", "customer_id", sale.ClientId, "error", err.Error())
 		}
 
 		supplier, err := h.UserClient.GetUser(c, &user.UserIDRequest{Id: sale.SoldBy, CompanyId: companyId})
 		if err == nil {
 			res.Sales[i].SoldByName = supplier.FirstName
 		} else {
-			h.log.Error("Error fetching customer details", "customer_id", sale.SoldBy, "error", err.Error())
+			h.log.ErrorContext(c, "Error fetching customer details", "customer_id", sale.SoldBy, "error", err.Error())
 		}
 	}
 
@@ -344,15 +382,15 @@ func (h *Handler) GetListSales(c *gin.Context) {
 // @Param id path string true "Sale ID"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.Message
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /sales/{id} [delete]
 func (h *Handler) DeleteSales(c *gin.Context) {
 	id := c.Param("id")
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -364,8 +402,8 @@ func (h *Handler) DeleteSales(c *gin.Context) {
 
 	res, err := h.ProductClient.DeleteSales(c, req)
 	if err != nil {
-		h.log.Error("Error deleting sale", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error deleting sale", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -381,29 +419,26 @@ func (h *Handler) DeleteSales(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Param Sale body entity.PaymentSale true "Sale data"
 // @Success 200 {object} products.SaleResponse
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /debts/payments [post]
 func (h *Handler) Payments(c *gin.Context) {
 	var req entity.PaymentSale
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Error parsing CalculateTotalSales request body", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error parsing Payments request body", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	sale := req.Proto()
+	sale.SoldBy = c.MustGet("id").(string)
+	sale.CompanyId = c.MustGet("company_id").(string)
+
 	if req.PaymentMethod == "cash" {
-		res, err := h.ProductClient.CalculateTotalSales(c, &products.SaleRequest{
-			ClientId:      req.ClientId,
-			SoldBy:        c.MustGet("id").(string),
-			CompanyId:     c.MustGet("company_id").(string),
-			PaymentMethod: req.PaymentMethod,
-			BranchId:      req.BranchId,
-			SoldProducts:  req.SoldProducts,
-		})
+		res, err := h.ProductClient.CalculateTotalSales(c, sale)
 		if err != nil {
-			h.log.Error("Error calculating total sales", "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			h.log.ErrorContext(c, "Error calculating total sales", "error", err.Error())
+			response.FromGRPC(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, res)
@@ -411,17 +446,10 @@ func (h *Handler) Payments(c *gin.Context) {
 	}
 	if req.PaymentMethod == "debt" {
 
-		res, err := h.ProductClient.CalculateTotalSales(c, &products.SaleRequest{
-			ClientId:      req.ClientId,
-			SoldBy:        c.MustGet("id").(string),
-			CompanyId:     c.MustGet("company_id").(string),
-			PaymentMethod: req.PaymentMethod,
-			BranchId:      req.BranchId,
-			SoldProducts:  req.SoldProducts,
-		})
+		res, err := h.ProductClient.CalculateTotalSales(c, sale)
 		if err != nil {
-			h.log.Error("Error calculating total sales", "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			h.log.ErrorContext(c, "Error calculating total sales", "error", err.Error())
+			response.FromGRPC(c, err)
 			return
 		}
 
@@ -432,8 +460,8 @@ func (h *Handler) Payments(c *gin.Context) {
 			CompanyId:    c.MustGet("company_id").(string),
 		})
 		if err != nil {
-			h.log.Error("Error creating debt", "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			h.log.ErrorContext(c, "Error creating debt", "error", err.Error())
+			response.FromGRPC(c, err)
 			return
 		}
 		if !req.IsFullyDebt {
@@ -443,8 +471,8 @@ func (h *Handler) Payments(c *gin.Context) {
 				CompanyId:  c.MustGet("company_id").(string),
 			})
 			if err != nil {
-				h.log.Error("Error processing payment", "error", err.Error())
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				h.log.ErrorContext(c, "Error processing payment", "error", err.Error())
+				response.FromGRPC(c, err)
 				return
 			}
 			c.JSON(http.StatusOK, gin.H{
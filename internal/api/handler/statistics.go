@@ -2,15 +2,14 @@ package handler
 
 import (
 	"context"
-	"fmt"
+	"gateway/internal/api/middleware"
+	"gateway/internal/api/response"
 	"gateway/internal/entity"
 	"gateway/internal/generated/products"
 	pbu "gateway/internal/generated/user"
 	"github.com/gin-gonic/gin"
-	"log"
 	"net/http"
 	"strconv"
-	"time"
 )
 
 // TotalPriceOfProducts godoc
@@ -24,54 +23,38 @@ import (
 // @Param end_date query string true "End Date (YYYY-MM-DD)"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.PriceProducts
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /statistics/products/total-price [get]
 func (h *Handler) TotalPriceOfProducts(c *gin.Context) {
 	companyId := c.MustGet("company_id").(string)
 
-	startDate := c.DefaultQuery("start_date", "")
-	endDate := c.DefaultQuery("end_date", "")
-
-	if startDate == "" || endDate == "" {
-		h.log.Error("Missing required query parameters: start_date or end_date")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date are required"})
-		return
-	}
-
-	layout := "2006-01-02" // Человечный формат
-	parsedStartDate, err := time.Parse(layout, startDate)
-	if err != nil {
-		h.log.Error("Invalid start_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, expected YYYY-MM-DD"})
-		return
-	}
-
-	parsedEndDate, err := time.Parse(layout, endDate)
-	if err != nil {
-		h.log.Error("Invalid end_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, expected YYYY-MM-DD"})
+	var dates entity.DateRange
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		h.log.ErrorContext(c, "Invalid date range", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	startDate, endDate := dates.RFC3339()
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	req := &products.StatisticReq{
 		CompanyId: companyId,
-		StartDate: parsedStartDate.Format(time.RFC3339),
-		EndDate:   parsedEndDate.Format(time.RFC3339),
+		StartDate: startDate,
+		EndDate:   endDate,
 		BranchId:  branchId,
 	}
 
 	// Call the gRPC method
 	res, err := h.ProductClient.TotalPriceOfProducts(c, req)
 	if err != nil {
-		h.log.Error("Error calculating total price of products", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error calculating total price of products", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -89,54 +72,38 @@ func (h *Handler) TotalPriceOfProducts(c *gin.Context) {
 // @Param end_date query string true "End Date (YYYY-MM-DD)"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.PriceProducts
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /statistics/products/total-sold [get]
 func (h *Handler) TotalSoldProducts(c *gin.Context) {
 	companyId := c.MustGet("company_id").(string)
 
-	startDate := c.DefaultQuery("start_date", "")
-	endDate := c.DefaultQuery("end_date", "")
-
-	if startDate == "" || endDate == "" {
-		h.log.Error("Missing required query parameters: start_date or end_date")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date are required"})
-		return
-	}
-
-	layout := "2006-01-02" // Человечный формат
-	parsedStartDate, err := time.Parse(layout, startDate)
-	if err != nil {
-		h.log.Error("Invalid start_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, expected YYYY-MM-DD"})
-		return
-	}
-
-	parsedEndDate, err := time.Parse(layout, endDate)
-	if err != nil {
-		h.log.Error("Invalid end_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, expected YYYY-MM-DD"})
+	var dates entity.DateRange
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		h.log.ErrorContext(c, "Invalid date range", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	startDate, endDate := dates.RFC3339()
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	req := &products.StatisticReq{
 		CompanyId: companyId,
-		StartDate: parsedStartDate.Format(time.RFC3339),
-		EndDate:   parsedEndDate.Format(time.RFC3339),
+		StartDate: startDate,
+		EndDate:   endDate,
 		BranchId:  branchId,
 	}
 
 	// Call the gRPC method
 	res, err := h.ProductClient.TotalSoldProducts(c, req)
 	if err != nil {
-		h.log.Error("Error calculating total sold products", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error calculating total sold products", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -154,54 +121,38 @@ func (h *Handler) TotalSoldProducts(c *gin.Context) {
 // @Param end_date query string true "End Date (YYYY-MM-DD)"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.PriceProducts
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /statistics/products/total-purchased [get]
 func (h *Handler) TotalPurchaseProducts(c *gin.Context) {
 	companyId := c.MustGet("company_id").(string)
 
-	startDate := c.DefaultQuery("start_date", "")
-	endDate := c.DefaultQuery("end_date", "")
-
-	if startDate == "" || endDate == "" {
-		h.log.Error("Missing required query parameters: start_date or end_date")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date are required"})
-		return
-	}
-
-	layout := "2006-01-02" // Человечный формат
-	parsedStartDate, err := time.Parse(layout, startDate)
-	if err != nil {
-		h.log.Error("Invalid start_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, expected YYYY-MM-DD"})
-		return
-	}
-
-	parsedEndDate, err := time.Parse(layout, endDate)
-	if err != nil {
-		h.log.Error("Invalid end_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, expected YYYY-MM-DD"})
+	var dates entity.DateRange
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		h.log.ErrorContext(c, "Invalid date range", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	startDate, endDate := dates.RFC3339()
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	req := &products.StatisticReq{
 		CompanyId: companyId,
-		StartDate: parsedStartDate.Format(time.RFC3339),
-		EndDate:   parsedEndDate.Format(time.RFC3339),
+		StartDate: startDate,
+		EndDate:   endDate,
 		BranchId:  branchId,
 	}
 
 	// Call the gRPC method
 	res, err := h.ProductClient.TotalPurchaseProducts(c, req)
 	if err != nil {
-		h.log.Error("Error calculating total purchase products", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error calculating total purchase products", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -219,53 +170,35 @@ func (h *Handler) TotalPurchaseProducts(c *gin.Context) {
 // @Param end_date query string true "End Date (YYYY-MM-DD)"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.MostSoldProductsResponse
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /statistics/products/most-sold [get]
 func (h *Handler) GetMostSoldProductsByDay(c *gin.Context) {
 
-	log.Println("Mana keldi")
-
 	companyId := c.MustGet("company_id").(string)
-	branchId := c.GetHeader("branch_id") // Extract branch_id from header
-
-	startDate := c.DefaultQuery("start_date", "")
-	endDate := c.DefaultQuery("end_date", "")
-
-	if startDate == "" || endDate == "" {
-		h.log.Error("Missing required query parameters: start_date or end_date")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date are required"})
-		return
-	}
-
-	layout := "2006-01-02"
-	parsedStartDate, err := time.Parse(layout, startDate)
-	if err != nil {
-		h.log.Error("Invalid start_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, expected YYYY-MM-DD"})
-		return
-	}
+	branchId := c.GetString(middleware.BranchKey)
 
-	parsedEndDate, err := time.Parse(layout, endDate)
-	if err != nil {
-		h.log.Error("Invalid end_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, expected YYYY-MM-DD"})
+	var dates entity.DateRange
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		h.log.ErrorContext(c, "Invalid date range", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	startDate, endDate := dates.RFC3339()
 
 	req := &products.MostSoldProductsRequest{
 		CompanyId: companyId,
 		BranchId:  branchId, // Pass branch_id from header
-		StartDate: parsedStartDate.Format(time.RFC3339),
-		EndDate:   parsedEndDate.Format(time.RFC3339),
+		StartDate: startDate,
+		EndDate:   endDate,
 	}
 
-	h.log.Info("GetMostSoldProductsByDay", "req", req.CompanyId)
+	h.log.InfoContext(c, "GetMostSoldProductsByDay", "req", req.CompanyId)
 
 	res, err := h.ProductClient.GetMostSoldProductsByDay(c, req)
 	if err != nil {
-		h.log.Error("Error getting most sold products by day", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error getting most sold products by day", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -283,53 +216,37 @@ func (h *Handler) GetMostSoldProductsByDay(c *gin.Context) {
 // @Param end_date query string true "End Date (YYYY-MM-DD)"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.GetTopEntitiesResponse
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /statistics/top-clients [get]
 func (h *Handler) GetTopClients(c *gin.Context) {
 	companyId := c.MustGet("company_id").(string)
 
-	startDate := c.DefaultQuery("start_date", "")
-	endDate := c.DefaultQuery("end_date", "")
-
-	if startDate == "" || endDate == "" {
-		h.log.Error("Missing required query parameters: start_date or end_date")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date are required"})
-		return
-	}
-
-	layout := "2006-01-02" // Человечный формат
-	parsedStartDate, err := time.Parse(layout, startDate)
-	if err != nil {
-		h.log.Error("Invalid start_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, expected YYYY-MM-DD"})
-		return
-	}
-
-	parsedEndDate, err := time.Parse(layout, endDate)
-	if err != nil {
-		h.log.Error("Invalid end_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, expected YYYY-MM-DD"})
+	var dates entity.DateRange
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		h.log.ErrorContext(c, "Invalid date range", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	startDate, endDate := dates.RFC3339()
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	req := &products.GetTopEntitiesRequest{
 		CompanyId: companyId,
-		StartDate: parsedStartDate.Format(time.RFC3339), // Переводим в RFC3339 для передачи
-		EndDate:   parsedEndDate.Format(time.RFC3339),
+		StartDate: startDate, // Переводим в RFC3339 для передачи
+		EndDate:   endDate,
 		BranchId:  branchId,
 	}
 
 	res, err := h.ProductClient.GetTopClients(c, req)
 	if err != nil {
-		h.log.Error("Error getting top clients", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error getting top clients", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -345,7 +262,7 @@ func (h *Handler) GetTopClients(c *gin.Context) {
 			topClient.Phone = cl.Phone
 			topClient.TotalSum = clientID.TotalValue
 		} else {
-			h.log.Error("Error getting client id", "error", err.Error())
+			h.log.ErrorContext(c, "Error getting client id", "error", err.Error())
 			topClient.ID = clientID.SupplierId
 			topClient.TotalSum = clientID.TotalValue
 		}
@@ -367,53 +284,37 @@ func (h *Handler) GetTopClients(c *gin.Context) {
 // @Param end_date query string true "End Date (YYYY-MM-DD)"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.GetTopEntitiesResponse
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /statistics/top-suppliers [get]
 func (h *Handler) GetTopSuppliers(c *gin.Context) {
 	companyId := c.MustGet("company_id").(string)
 
-	startDate := c.DefaultQuery("start_date", "")
-	endDate := c.DefaultQuery("end_date", "")
-
-	if startDate == "" || endDate == "" {
-		h.log.Error("Missing required query parameters: start_date or end_date")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date are required"})
-		return
-	}
-
-	layout := "2006-01-02" // Человечный формат
-	parsedStartDate, err := time.Parse(layout, startDate)
-	if err != nil {
-		h.log.Error("Invalid start_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, expected YYYY-MM-DD"})
-		return
-	}
-
-	parsedEndDate, err := time.Parse(layout, endDate)
-	if err != nil {
-		h.log.Error("Invalid end_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, expected YYYY-MM-DD"})
+	var dates entity.DateRange
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		h.log.ErrorContext(c, "Invalid date range", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	startDate, endDate := dates.RFC3339()
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	req := &products.GetTopEntitiesRequest{
 		CompanyId: companyId,
-		StartDate: parsedStartDate.Format(time.RFC3339),
-		EndDate:   parsedEndDate.Format(time.RFC3339),
+		StartDate: startDate,
+		EndDate:   endDate,
 		BranchId:  branchId,
 	}
 
 	res, err := h.ProductClient.GetTopSuppliers(c, req)
 	if err != nil {
-		h.log.Error("Error getting top suppliers", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error getting top suppliers", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -429,7 +330,7 @@ func (h *Handler) GetTopSuppliers(c *gin.Context) {
 			topSupplier.Phone = cl.Phone
 			topSupplier.TotalSum = supplier.TotalValue
 		} else {
-			h.log.Error("Error getting supplier id", "error", err.Error())
+			h.log.ErrorContext(c, "Error getting supplier id", "error", err.Error())
 			topSupplier.ID = supplier.SupplierId
 			topSupplier.TotalSum = supplier.TotalValue
 		}
@@ -451,54 +352,38 @@ func (h *Handler) GetTopSuppliers(c *gin.Context) {
 // @Param end_date query string true "End Date (YYYY-MM-DD)"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.PriceProducts
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /statistics/cash/total-income [get]
 func (h *Handler) GetTotalIncome(c *gin.Context) {
 	companyId := c.MustGet("company_id").(string)
 
-	startDate := c.DefaultQuery("start_date", "")
-	endDate := c.DefaultQuery("end_date", "")
-
-	if startDate == "" || endDate == "" {
-		h.log.Error("Missing required query parameters: start_date or end_date")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date are required"})
-		return
-	}
-
-	layout := "2006-01-02"
-	parsedStartDate, err := time.Parse(layout, startDate)
-	if err != nil {
-		h.log.Error("Invalid start_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, expected YYYY-MM-DD"})
-		return
-	}
-
-	parsedEndDate, err := time.Parse(layout, endDate)
-	if err != nil {
-		h.log.Error("Invalid end_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, expected YYYY-MM-DD"})
+	var dates entity.DateRange
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		h.log.ErrorContext(c, "Invalid date range", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	startDate, endDate := dates.RFC3339()
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	req := &products.StatisticReq{
 		CompanyId: companyId,
-		StartDate: parsedStartDate.Format(time.RFC3339),
-		EndDate:   parsedEndDate.Format(time.RFC3339),
+		StartDate: startDate,
+		EndDate:   endDate,
 		BranchId:  branchId,
 	}
 
 	// Call the repository method
 	res, err := h.ProductClient.GetTotalIncome(context.Background(), req)
 	if err != nil {
-		h.log.Error("Error calculating total income", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error calculating total income", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -516,54 +401,38 @@ func (h *Handler) GetTotalIncome(c *gin.Context) {
 // @Param end_date query string true "End Date (YYYY-MM-DD)"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.PriceProducts
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /statistics/cash/total-expense [get]
 func (h *Handler) GetTotalExpense(c *gin.Context) {
 	companyId := c.MustGet("company_id").(string)
 
-	startDate := c.DefaultQuery("start_date", "")
-	endDate := c.DefaultQuery("end_date", "")
-
-	if startDate == "" || endDate == "" {
-		h.log.Error("Missing required query parameters: start_date or end_date")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date are required"})
-		return
-	}
-
-	layout := "2006-01-02"
-	parsedStartDate, err := time.Parse(layout, startDate)
-	if err != nil {
-		h.log.Error("Invalid start_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, expected YYYY-MM-DD"})
-		return
-	}
-
-	parsedEndDate, err := time.Parse(layout, endDate)
-	if err != nil {
-		h.log.Error("Invalid end_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, expected YYYY-MM-DD"})
+	var dates entity.DateRange
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		h.log.ErrorContext(c, "Invalid date range", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	startDate, endDate := dates.RFC3339()
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	req := &products.StatisticReq{
 		CompanyId: companyId,
-		StartDate: parsedStartDate.Format(time.RFC3339),
-		EndDate:   parsedEndDate.Format(time.RFC3339),
+		StartDate: startDate,
+		EndDate:   endDate,
 		BranchId:  branchId,
 	}
 
 	// Call the repository method
 	res, err := h.ProductClient.GetTotalExpense(context.Background(), req)
 	if err != nil {
-		h.log.Error("Error calculating total expense", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error calculating total expense", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -581,54 +450,38 @@ func (h *Handler) GetTotalExpense(c *gin.Context) {
 // @Param end_date query string true "End Date (YYYY-MM-DD)"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.PriceProducts
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /statistics/cash/net-profit [get]
 func (h *Handler) GetNetProfit(c *gin.Context) {
 	companyId := c.MustGet("company_id").(string)
 
-	startDate := c.DefaultQuery("start_date", "")
-	endDate := c.DefaultQuery("end_date", "")
-
-	if startDate == "" || endDate == "" {
-		h.log.Error("Missing required query parameters: start_date or end_date")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date are required"})
-		return
-	}
-
-	layout := "2006-01-02"
-	parsedStartDate, err := time.Parse(layout, startDate)
-	if err != nil {
-		h.log.Error("Invalid start_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, expected YYYY-MM-DD"})
-		return
-	}
-
-	parsedEndDate, err := time.Parse(layout, endDate)
-	if err != nil {
-		h.log.Error("Invalid end_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, expected YYYY-MM-DD"})
+	var dates entity.DateRange
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		h.log.ErrorContext(c, "Invalid date range", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	startDate, endDate := dates.RFC3339()
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	req := &products.StatisticReq{
 		CompanyId: companyId,
-		StartDate: parsedStartDate.Format(time.RFC3339),
-		EndDate:   parsedEndDate.Format(time.RFC3339),
+		StartDate: startDate,
+		EndDate:   endDate,
 		BranchId:  branchId,
 	}
 
 	// Call the repository method
 	res, err := h.ProductClient.GetNetProfit(context.Background(), req)
 	if err != nil {
-		h.log.Error("Error calculating net profit", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error calculating net profit", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -651,15 +504,13 @@ func (h *Handler) GetNetProfit(c *gin.Context) {
 // @Param payment_method query string false "Payment Method (uzs | usd | card)"
 // @Param branch_id header string true "Branch ID of the company"
 // @Success 200 {object} products.ListCashFlow "List of cash flow transactions with pagination details"
-// @Failure 400 {object} products.Error "Invalid input parameters or missing required values"
-// @Failure 500 {object} products.Error "Internal server error"
+// @Failure 400 {object} entity.Error "Invalid input parameters or missing required values"
+// @Failure 500 {object} entity.Error "Internal server error"
 // @Router /cash-flow [get]
 func (h *Handler) GetCashFlow(c *gin.Context) {
 
 	companyId := c.MustGet("company_id").(string)
 	description := c.Query("description")
-	startDate := c.DefaultQuery("start_date", "")
-	endDate := c.DefaultQuery("end_date", "")
 	transactionType := c.Query("transaction_type")
 	paymentMethod := c.Query("payment_method")
 	limit := c.Query("limit")
@@ -675,38 +526,25 @@ func (h *Handler) GetCashFlow(c *gin.Context) {
 		pageInt = 1 // Default page
 	}
 
-	if startDate == "" || endDate == "" {
-		h.log.Error("Missing required query parameters: start_date or end_date")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date are required"})
-		return
-	}
-
-	layout := "2006-01-02"
-	parsedStartDate, err := time.Parse(layout, startDate)
-	if err != nil {
-		h.log.Error("Invalid start_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, expected YYYY-MM-DD"})
-		return
-	}
-
-	parsedEndDate, err := time.Parse(layout, endDate)
-	if err != nil {
-		h.log.Error("Invalid end_date format", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, expected YYYY-MM-DD"})
+	var dates entity.DateRange
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		h.log.ErrorContext(c, "Invalid date range", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	startDate, endDate := dates.RFC3339()
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	req := &products.CashFlowReq{
 		CompanyId:       companyId,
 		BranchId:        branchId,
-		StartDate:       parsedStartDate.Format(time.RFC3339),
-		EndDate:         parsedEndDate.Format(time.RFC3339),
+		StartDate:       startDate,
+		EndDate:         endDate,
 		TransactionType: transactionType,
 		PaymentMethod:   paymentMethod,
 		Description:     description,
@@ -716,8 +554,8 @@ func (h *Handler) GetCashFlow(c *gin.Context) {
 
 	res, err := h.ProductClient.GetCashFlow(c, req)
 	if err != nil {
-		h.log.Error("Error getting cash flow", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error getting cash flow", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -731,25 +569,26 @@ func (h *Handler) GetCashFlow(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Param request body products.CashFlowRequest true "Income Cash Flow Data"
+// @Param request body entity.CashFlowRequest true "Income Cash Flow Data"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.CashFlow
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /cash-flow/income [post]
 func (h *Handler) CreateIncome(c *gin.Context) {
 
-	var request products.CashFlowRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		h.log.Error("Invalid request data", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+	var body entity.CashFlowRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "Invalid request data", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	request := body.Proto()
 
 	// Получаем branch_id из заголовка
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -761,10 +600,10 @@ func (h *Handler) CreateIncome(c *gin.Context) {
 	request.UserId = c.MustGet("id").(string)
 
 	// Создание дохода
-	res, err := h.ProductClient.CreateIncome(c, &request)
+	res, err := h.ProductClient.CreateIncome(c, request)
 	if err != nil {
-		h.log.Error("Error creating income", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error creating income", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -778,25 +617,26 @@ func (h *Handler) CreateIncome(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Param request body products.CashFlowRequest true "Expense Cash Flow Data"
+// @Param request body entity.CashFlowRequest true "Expense Cash Flow Data"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.CashFlow
-// @Failure 400 {object} products.Error
-// @Failure 500 {object} products.Error
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error
 // @Router /cash-flow/expense [post]
 func (h *Handler) CreateExpense(c *gin.Context) {
 
-	var request products.CashFlowRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		h.log.Error("Invalid request data", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+	var body entity.CashFlowRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "Invalid request data", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	request := body.Proto()
 
 	// Получаем branch_id из заголовка
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
@@ -808,10 +648,10 @@ func (h *Handler) CreateExpense(c *gin.Context) {
 	request.CompanyId = c.MustGet("company_id").(string)
 
 	// Создание расхода
-	res, err := h.ProductClient.CreateExpense(c, &request)
+	res, err := h.ProductClient.CreateExpense(c, request)
 	if err != nil {
-		h.log.Error("Error creating expense", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error creating expense", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -830,30 +670,37 @@ func (h *Handler) CreateExpense(c *gin.Context) {
 // @Param period query string false "Period (e.g., daily, weekly, monthly)"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.SaleStatistics
-// @Failure 400 {object} products.Error "Branch ID is required in the header"
-// @Failure 500 {object} products.Error "Internal server error"
+// @Failure 400 {object} entity.Error "Branch ID is required in the header"
+// @Failure 500 {object} entity.Error "Internal server error"
 // @Router /statistics/sale-statistics [get]
 func (h *Handler) GetSaleStatistics(c *gin.Context) {
 
 	var req products.SaleStatisticsReq
 
-	req.StartDate = c.Query("start_date")
-	req.EndDate = c.Query("end_date")
+	var dates entity.DateFilter
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		h.log.ErrorContext(c, "Invalid date range", "error", err.Error())
+		response.Invalid(c, err)
+		return
+	}
+
+	req.StartDate = dates.StartDate
+	req.EndDate = dates.EndDate
 	req.Period = c.Query("period")
 
 	req.CompanyId = c.MustGet("company_id").(string)
 
-	req.BranchId = c.GetHeader("branch_id")
+	req.BranchId = c.GetString(middleware.BranchKey)
 	if req.BranchId == "" {
-		h.log.Error("Branch ID is required in the header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		h.log.ErrorContext(c, "Branch ID is required in the header")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
 	res, err := h.ProductClient.GetSaleStatistics(c, &req)
 	if err != nil {
-		h.log.Error("Error getting sale statistics", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error getting sale statistics", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -870,20 +717,28 @@ func (h *Handler) GetSaleStatistics(c *gin.Context) {
 // @Param start_date query string false "Start date (YYYY-MM-DD)"
 // @Param end_date query string false "End date (YYYY-MM-DD)"
 // @Success 200 {object} products.BranchIncomeRes
-// @Failure 500 {object} products.Error "Internal server error"
+// @Failure 400 {object} entity.Error
+// @Failure 500 {object} entity.Error "Internal server error"
 // @Router /statistics/branch-income [get]
 func (h *Handler) GetBranchIncome(c *gin.Context) {
 
 	var req products.BranchIncomeReq
 
-	req.StartDate = c.Query("start_date")
-	req.EndDate = c.Query("end_date")
+	var dates entity.DateFilter
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		h.log.ErrorContext(c, "Invalid date range", "error", err.Error())
+		response.Invalid(c, err)
+		return
+	}
+
+	req.StartDate = dates.StartDate
+	req.EndDate = dates.EndDate
 	req.CompanyId = c.MustGet("company_id").(string)
 
 	res, err := h.ProductClient.GetBranchIncome(c, &req)
 	if err != nil {
-		h.log.Error("Error getting branch income", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error getting branch income", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -900,14 +755,14 @@ func (h *Handler) GetBranchIncome(c *gin.Context) {
 // @Param client_id path string true "Client ID"
 // @Param branch_id header string true "Branch ID"
 // @Success 200 {object} products.GetClientDashboardResponse
-// @Failure 500 {object} products.Error "Internal server error"
+// @Failure 500 {object} entity.Error "Internal server error"
 // @Router /statistics/client-dashboard/{client_id} [get]
 func (h *Handler) GetClientDashboard(c *gin.Context) {
 	clientId := c.Param("client_id")
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		h.log.Error("Branch ID is required in the header")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		h.log.ErrorContext(c, "Branch ID is required in the header")
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 	req := products.GetClientDashboardRequest{
@@ -915,11 +770,11 @@ func (h *Handler) GetClientDashboard(c *gin.Context) {
 		BranchId:  branchId,
 		ClientId:  clientId,
 	}
-	fmt.Println("Client ID:", clientId, "Branch ID:", branchId, "Company ID:", req.CompanyId)
+	h.log.DebugContext(c, "Fetching client dashboard", "client_id", clientId)
 	res, err := h.ProductClient.GetClientDashboard(c, &req)
 	if err != nil {
-		h.log.Error("Error getting client dashboard", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error getting client dashboard", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
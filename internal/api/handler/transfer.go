@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"gateway/internal/api/middleware"
+	"gateway/internal/api/response"
+	"gateway/internal/entity"
 	"gateway/internal/generated/products"
 	"github.com/gin-gonic/gin"
-	"log"
 	"net/http"
 	"strconv"
 )
@@ -22,28 +24,41 @@ import (
 // @Failure 500 {object} entity.Error
 // @Router /transfers [post]
 func (h *Handler) CreateTransfers(c *gin.Context) {
-	var req products.TransferReq
+	var body entity.TransferReq
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("Error parsing CreateTransfers request body", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "Error parsing CreateTransfers request body", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
 
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
+	req := body.Proto()
 	req.CompanyId = c.MustGet("company_id").(string)
 	req.TransferredBy = c.MustGet("id").(string)
 	req.FromBranchId = branchId
 
-	res, err := h.ProductClient.CreateTransfers(c, &req)
+	// The destination may be any branch of the company, but never another company's.
+	inCompany, err := h.Branches.InCompany(c.Request.Context(), req.CompanyId, req.ToBranchId)
 	if err != nil {
-		h.log.Error("Error creating transfer", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error resolving company branches", "error", err.Error())
+		response.FromGRPC(c, err)
+		return
+	}
+	if !inCompany {
+		response.Error(c, http.StatusBadRequest, "to_branch_id does not belong to the company")
+		return
+	}
+
+	res, err := h.ProductClient.CreateTransfers(c, req)
+	if err != nil {
+		h.log.ErrorContext(c, "Error creating transfer", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -71,8 +86,8 @@ func (h *Handler) GetTransfers(c *gin.Context) {
 
 	res, err := h.ProductClient.GetTransfers(c, req)
 	if err != nil {
-		h.log.Error("Error fetching transfer", "transfer_id", id, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error fetching transfer", "transfer_id", id, "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -105,21 +120,21 @@ func (h *Handler) GetTransferList(c *gin.Context) {
 
 	limit, err := strconv.Atoi(limitSt)
 	if err != nil {
-		h.log.Error("Error parsing limit", "error", err.Error())
+		h.log.ErrorContext(c, "Error parsing limit", "error", err.Error())
 		limit = 0
 	}
 	page, err := strconv.Atoi(pageSt)
 	if err != nil {
-		h.log.Error("Error parsing page", "error", err.Error())
+		h.log.ErrorContext(c, "Error parsing page", "error", err.Error())
 		page = 0
 	}
-	branchId := c.GetHeader("branch_id")
+	branchId := c.GetString(middleware.BranchKey)
 	if branchId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch ID is required in the header"})
+		response.Error(c, http.StatusBadRequest, "Branch ID is required in the header")
 		return
 	}
 
-	log.Println(limit, page)
+	h.log.DebugContext(c, "Listing transfers", "limit", limit, "page", page)
 
 	filter.Limit = int64(limit)
 	filter.Page = int64(page)
@@ -128,8 +143,8 @@ func (h *Handler) GetTransferList(c *gin.Context) {
 
 	res, err := h.ProductClient.GetTransferList(c, &filter)
 	if err != nil {
-		h.log.Error("Error retrieving transfer list", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "Error retrieving transfer list", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -1,13 +1,13 @@
 package handler
 
 import (
-	"fmt"
+	"errors"
+	"gateway/internal/api/response"
 	"gateway/internal/api/token"
 	"gateway/internal/entity"
 	user "gateway/internal/generated/user"
 	"github.com/gin-gonic/gin"
 	"net/http"
-	"time"
 )
 
 // RegisterAdmin godoc
@@ -25,7 +25,7 @@ func (a *Handler) RegisterAdmin(c *gin.Context) {
 
 	if err != nil {
 		a.log.Error("Error registering admin", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -47,12 +47,11 @@ func (a *Handler) Login(c *gin.Context) {
 	var req user.LogInRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		a.log.Error("Error parsing request body", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
-	a.log.Debug(fmt.Sprintf("[%s] Login phone: %s", time.Now().Format("02-01-2006 15:04:05"), req.PhoneNumber))
+	a.log.DebugContext(c, "Login attempt", "phone", req.PhoneNumber)
 
 	res, err := a.UserClient.LogIn(c.Request.Context(), &user.LogInRequest{
 		PhoneNumber: req.PhoneNumber,
@@ -61,11 +60,36 @@ func (a *Handler) Login(c *gin.Context) {
 
 	if err != nil {
 		a.log.Error("Error logging in", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		response.FromGRPC(c, err)
 		return
 	}
 
-	c.SetCookie("access_token_smart_admin", res.RefreshToken, 604800, "/", "/", true, false)
+	claims, err := a.Tokens.ExtractToken(res.RefreshToken, false)
+	if err != nil {
+		a.log.Error("Error extracting refresh token from user service", "error", err)
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	res.RefreshToken, err = a.Tokens.IssueRefreshToken(claims)
+	if err != nil {
+		a.log.Error("Error issuing refresh token", "error", err)
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	// Access tokens from the user service are signed with the shared secret;
+	// reissue them with the gateway's own key when one is configured.
+	if a.Tokens.Keys() != nil {
+		res.AccessToken, err = a.Tokens.GenerateAccessToken(claims)
+		if err != nil {
+			a.log.Error("Error generating access token", "error", err)
+			response.Error(c, http.StatusInternalServerError, "could not generate access token")
+			return
+		}
+	}
+
+	a.setRefreshCookie(c, res.RefreshToken)
 
 	c.JSON(http.StatusOK, res)
 }
@@ -85,8 +109,7 @@ func (a *Handler) CreateUser(c *gin.Context) {
 	var req entity.UserUpdateRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		a.log.Error("Error parsing request body", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
@@ -101,7 +124,7 @@ func (a *Handler) CreateUser(c *gin.Context) {
 
 	if err != nil {
 		a.log.Error("Error creating user", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -124,8 +147,7 @@ func (a *Handler) UpdateUser(c *gin.Context) {
 	var req entity.UserUpdateRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		a.log.Error("Error parsing request body", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
@@ -142,7 +164,7 @@ func (a *Handler) UpdateUser(c *gin.Context) {
 
 	if err != nil {
 		a.log.Error("Error updating user", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -169,7 +191,7 @@ func (a *Handler) DeleteUser(c *gin.Context) {
 
 	if err != nil {
 		a.log.Error("Error deleting user", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -196,7 +218,7 @@ func (a *Handler) GetUser(c *gin.Context) {
 
 	if err != nil {
 		a.log.Error("Error retrieving user", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -218,8 +240,7 @@ func (a *Handler) ListUser(c *gin.Context) {
 	var req user.FilterUserRequest
 
 	if err := c.ShouldBindQuery(&req); err != nil {
-		a.log.Error("Error parsing query parameters", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Invalid(c, err)
 		return
 	}
 
@@ -231,7 +252,7 @@ func (a *Handler) ListUser(c *gin.Context) {
 
 	if err != nil {
 		a.log.Error("Error retrieving user list", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		response.FromGRPC(c, err)
 		return
 	}
 
@@ -240,40 +261,127 @@ func (a *Handler) ListUser(c *gin.Context) {
 
 // GetAccessToken godoc
 // @Summary Access Token
-// @Description Get Access token with refresh token
+// @Description Get Access token with refresh token. The refresh token cookie is rotated on every call;
+// @Description presenting an already used refresh token revokes every session of that login, unless it is
+// @Description presented again within REFRESH_REUSE_GRACE seconds of its first use (e.g. by a second tab).
 // @Tags User
 // @Accept json
 // @Produce json
 // @Success 200 {object} Token
-// @Failure 400 {object} entity.Error
+// @Failure 401 {object} entity.Error
 // @Failure 500 {object} entity.Error
 // @Router /user/get/access-token [post]
 func (a *Handler) GetAccessToken(c *gin.Context) {
 
-	toknC, err := c.Cookie("access_token_smart_admin")
+	toknC, err := c.Cookie(refreshCookie)
 	if err != nil {
 		a.log.Error("Error retrieving token", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		response.Error(c, http.StatusUnauthorized, "refresh token is missing")
 		return
 	}
 
-	claims, err := token.ExtractToken(toknC, false)
+	claims, refreshToken, err := a.Tokens.RotateRefreshToken(c.Request.Context(), toknC)
+	if errors.Is(err, token.ErrTokenReused) {
+		a.log.Warn("Refresh token reuse detected, token family revoked", "user_id", claims.Id)
+		clearRefreshCookie(c)
+		response.Error(c, http.StatusUnauthorized, "refresh token has already been used")
+		return
+	}
 	if err != nil {
-		a.log.Error("Error extracting token", "error", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired refresh token"})
+		a.log.Error("Error rotating refresh token", "error", err)
+		clearRefreshCookie(c)
+		response.Error(c, http.StatusUnauthorized, "invalid or expired refresh token")
 		return
 	}
 
-	accessToken, err := token.GenerateAccessToken(claims)
+	accessToken, err := a.Tokens.GenerateAccessToken(claims)
 	if err != nil {
 		a.log.Error("Error generating access token", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not generate access token"})
+		response.Error(c, http.StatusInternalServerError, "could not generate access token")
 		return
 	}
 
+	a.setRefreshCookie(c, refreshToken)
+
 	c.JSON(http.StatusOK, gin.H{"access_token": accessToken})
 }
 
+// Logout godoc
+// @Summary Logout
+// @Description Revoke the refresh token of the current session and clear its cookie
+// @Tags User
+// @Accept json
+// @Produce json
+// @Success 200 {object} user.MessageResponse
+// @Failure 401 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /user/logout [post]
+func (a *Handler) Logout(c *gin.Context) {
+	toknC, err := c.Cookie(refreshCookie)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "refresh token is missing")
+		return
+	}
+
+	if _, err := a.Tokens.RevokeRefreshToken(c.Request.Context(), toknC); err != nil {
+		a.log.Error("Error revoking refresh token", "error", err)
+		clearRefreshCookie(c)
+		response.Error(c, http.StatusUnauthorized, "invalid or expired refresh token")
+		return
+	}
+
+	clearRefreshCookie(c)
+
+	c.JSON(http.StatusOK, user.MessageResponse{Message: "logged out"})
+}
+
+// LogoutAll godoc
+// @Summary Logout from all devices
+// @Description Revoke every refresh and access token issued to the current user
+// @Tags User
+// @Accept json
+// @Produce json
+// @Success 200 {object} user.MessageResponse
+// @Failure 401 {object} entity.Error
+// @Failure 500 {object} entity.Error
+// @Router /user/logout/all [post]
+func (a *Handler) LogoutAll(c *gin.Context) {
+	toknC, err := c.Cookie(refreshCookie)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "refresh token is missing")
+		return
+	}
+
+	claims, err := a.Tokens.RevokeRefreshToken(c.Request.Context(), toknC)
+	if err != nil {
+		a.log.Error("Error revoking refresh token", "error", err)
+		clearRefreshCookie(c)
+		response.Error(c, http.StatusUnauthorized, "invalid or expired refresh token")
+		return
+	}
+
+	if err := a.Tokens.RevokeUser(c.Request.Context(), claims.Id); err != nil {
+		a.log.Error("Error revoking user sessions", "user_id", claims.Id, "error", err)
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	clearRefreshCookie(c)
+
+	c.JSON(http.StatusOK, user.MessageResponse{Message: "logged out from all devices"})
+}
+
+// refreshCookie holds the rotating refresh token.
+const refreshCookie = "access_token_smart_admin"
+
+func (a *Handler) setRefreshCookie(c *gin.Context, refreshToken string) {
+	c.SetCookie(refreshCookie, refreshToken, int(a.Tokens.RefreshTTL().Seconds()), "/", "/", true, false)
+}
+
+func clearRefreshCookie(c *gin.Context) {
+	c.SetCookie(refreshCookie, "", -1, "/", "/", true, false)
+}
+
 type Token struct {
 	Token string `json:"token"`
 }
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"gateway/internal/api/response"
+	"gateway/internal/entity"
 	"gateway/internal/generated/user"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -19,24 +21,25 @@ import (
 // @Failure 400 {object} entity.Error "Bad request"
 // @Router /salary [post]
 func (h *Handler) CreateSalary(c *gin.Context) {
-	var req user.SalaryRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("CreateSalary: error parsing request", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var body entity.SalaryRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "CreateSalary: error parsing request", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	req := body.Proto()
 	companyID, ok := c.Get("company_id")
 	if !ok {
-		h.log.Error("CreateSalary: company_id not found in context")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id not provided"})
+		h.log.ErrorContext(c, "CreateSalary: company_id not found in context")
+		response.Error(c, http.StatusBadRequest, "company_id not provided")
 		return
 	}
 	req.CompanyId = companyID.(string)
 
-	res, err := h.UserClient.CreateSalary(c.Request.Context(), &req)
+	res, err := h.UserClient.CreateSalary(c.Request.Context(), req)
 	if err != nil {
-		h.log.Error("CreateSalary: error creating salary", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "CreateSalary: error creating salary", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, res)
@@ -55,26 +58,27 @@ func (h *Handler) CreateSalary(c *gin.Context) {
 // @Failure 400 {object} entity.Error "Bad request"
 // @Router /salary/{salary_id} [put]
 func (h *Handler) UpdateSalary(c *gin.Context) {
-	var req user.SalaryUpdate
+	var body entity.SalaryUpdate
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("UpdateSalary: error parsing request", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "UpdateSalary: error parsing request", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	req := body.Proto()
 	req.SalaryId = c.Param("salary_id")
 	companyID, ok := c.Get("company_id")
 	if !ok {
-		h.log.Error("UpdateSalary: company_id not found in context")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id not provided"})
+		h.log.ErrorContext(c, "UpdateSalary: company_id not found in context")
+		response.Error(c, http.StatusBadRequest, "company_id not provided")
 		return
 	}
 	req.CompanyId = companyID.(string)
 
-	res, err := h.UserClient.UpdateSalary(c.Request.Context(), &req)
+	res, err := h.UserClient.UpdateSalary(c.Request.Context(), req)
 	if err != nil {
-		h.log.Error("UpdateSalary: error updating salary", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "UpdateSalary: error updating salary", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -96,8 +100,8 @@ func (h *Handler) GetSalaryByID(c *gin.Context) {
 	req.Id = c.Param("salary_id")
 	companyID, ok := c.Get("company_id")
 	if !ok {
-		h.log.Error("GetSalaryByID: company_id not found in context")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id not provided"})
+		h.log.ErrorContext(c, "GetSalaryByID: company_id not found in context")
+		response.Error(c, http.StatusBadRequest, "company_id not provided")
 		return
 	}
 
@@ -105,8 +109,8 @@ func (h *Handler) GetSalaryByID(c *gin.Context) {
 
 	res, err := h.UserClient.GetSalaryByID(c.Request.Context(), &req)
 	if err != nil {
-		h.log.Error("GetSalaryByID: error getting salary", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "GetSalaryByID: error getting salary", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -135,8 +139,8 @@ func (h *Handler) ListSalaries(c *gin.Context) {
 
 	companyID, ok := c.Get("company_id")
 	if !ok {
-		h.log.Error("ListSalaries: company_id not found in context")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id not provided"})
+		h.log.ErrorContext(c, "ListSalaries: company_id not found in context")
+		response.Error(c, http.StatusBadRequest, "company_id not provided")
 		return
 	}
 	req.CompanyId = companyID.(string)
@@ -146,8 +150,8 @@ func (h *Handler) ListSalaries(c *gin.Context) {
 	} else {
 		limit, err := strconv.Atoi(limitStr)
 		if err != nil {
-			h.log.Error("ListSalaries: error parsing limit", "error", err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			h.log.ErrorContext(c, "ListSalaries: error parsing limit", "error", err.Error())
+			response.Error(c, http.StatusBadRequest, "invalid limit")
 			return
 		}
 		req.Limit = int64(limit)
@@ -158,8 +162,8 @@ func (h *Handler) ListSalaries(c *gin.Context) {
 	} else {
 		page, err := strconv.Atoi(pageStr)
 		if err != nil {
-			h.log.Error("ListSalaries: error parsing page", "error", err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+			h.log.ErrorContext(c, "ListSalaries: error parsing page", "error", err.Error())
+			response.Error(c, http.StatusBadRequest, "invalid page")
 			return
 		}
 		req.Page = int64(page)
@@ -167,8 +171,8 @@ func (h *Handler) ListSalaries(c *gin.Context) {
 
 	res, err := h.UserClient.ListSalaries(c.Request.Context(), &req)
 	if err != nil {
-		h.log.Error("ListSalaries: error getting salaries", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "ListSalaries: error getting salaries", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -186,24 +190,25 @@ func (h *Handler) ListSalaries(c *gin.Context) {
 // @Failure 400 {object} entity.Error "Bad request"
 // @Router /adjustment [post]
 func (h *Handler) CreateAdjustment(c *gin.Context) {
-	var req user.AdjustmentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("CreateAdjustment: error parsing request", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var body entity.AdjustmentRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "CreateAdjustment: error parsing request", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	req := body.Proto()
 	companyID, ok := c.Get("company_id")
 	if !ok {
-		h.log.Error("CreateAdjustment: company_id not found in context")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id not provided"})
+		h.log.ErrorContext(c, "CreateAdjustment: company_id not found in context")
+		response.Error(c, http.StatusBadRequest, "company_id not provided")
 		return
 	}
 	req.CompanyId = companyID.(string)
 
-	res, err := h.UserClient.CreateAdjustment(c.Request.Context(), &req)
+	res, err := h.UserClient.CreateAdjustment(c.Request.Context(), req)
 	if err != nil {
-		h.log.Error("CreateAdjustment: error creating adjustment", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "CreateAdjustment: error creating adjustment", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, res)
@@ -222,25 +227,26 @@ func (h *Handler) CreateAdjustment(c *gin.Context) {
 // @Failure 400 {object} entity.Error "Bad request"
 // @Router /adjustment/{adjustment_id} [put]
 func (h *Handler) UpdateAdjustment(c *gin.Context) {
-	var req user.AdjustmentUpdate
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Error("UpdateAdjustment: error parsing request", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var body entity.AdjustmentUpdate
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log.ErrorContext(c, "UpdateAdjustment: error parsing request", "error", err.Error())
+		response.Invalid(c, err)
 		return
 	}
+	req := body.Proto()
 	req.AdjustmentId = c.Param("adjustment_id")
 	companyID, ok := c.Get("company_id")
 	if !ok {
-		h.log.Error("UpdateAdjustment: company_id not found in context")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id not provided"})
+		h.log.ErrorContext(c, "UpdateAdjustment: company_id not found in context")
+		response.Error(c, http.StatusBadRequest, "company_id not provided")
 		return
 	}
 	req.CompanyId = companyID.(string)
 
-	res, err := h.UserClient.UpdateAdjustment(c.Request.Context(), &req)
+	res, err := h.UserClient.UpdateAdjustment(c.Request.Context(), req)
 	if err != nil {
-		h.log.Error("UpdateAdjustment: error updating adjustment", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "UpdateAdjustment: error updating adjustment", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -262,16 +268,16 @@ func (h *Handler) CloseAdjustment(c *gin.Context) {
 	req.Id = c.Param("adjustment_id")
 	companyID, ok := c.Get("company_id")
 	if !ok {
-		h.log.Error("CloseAdjustment: company_id not found in context")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id not provided"})
+		h.log.ErrorContext(c, "CloseAdjustment: company_id not found in context")
+		response.Error(c, http.StatusBadRequest, "company_id not provided")
 		return
 	}
 	req.CompanyId = companyID.(string)
 
 	res, err := h.UserClient.CloseAdjustment(c.Request.Context(), &req)
 	if err != nil {
-		h.log.Error("CloseAdjustment: error closing adjustment", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "CloseAdjustment: error closing adjustment", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -293,16 +299,16 @@ func (h *Handler) GetAdjustmentByID(c *gin.Context) {
 	req.Id = c.Param("adjustment_id")
 	companyID, ok := c.Get("company_id")
 	if !ok {
-		h.log.Error("GetAdjustmentByID: company_id not found in context")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id not provided"})
+		h.log.ErrorContext(c, "GetAdjustmentByID: company_id not found in context")
+		response.Error(c, http.StatusBadRequest, "company_id not provided")
 		return
 	}
 	req.CompanyId = companyID.(string)
 
 	res, err := h.UserClient.GetAdjustmentByID(c.Request.Context(), &req)
 	if err != nil {
-		h.log.Error("GetAdjustmentByID: error getting adjustment", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "GetAdjustmentByID: error getting adjustment", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -335,8 +341,8 @@ func (h *Handler) ListAdjustments(c *gin.Context) {
 
 	companyID, ok := c.Get("company_id")
 	if !ok {
-		h.log.Error("ListAdjustments: company_id not found in context")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id not provided"})
+		h.log.ErrorContext(c, "ListAdjustments: company_id not found in context")
+		response.Error(c, http.StatusBadRequest, "company_id not provided")
 		return
 	}
 	req.CompanyId = companyID.(string)
@@ -346,8 +352,8 @@ func (h *Handler) ListAdjustments(c *gin.Context) {
 	} else {
 		limit, err := strconv.Atoi(limitStr)
 		if err != nil {
-			h.log.Error("ListAdjustments: error parsing limit", "error", err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			h.log.ErrorContext(c, "ListAdjustments: error parsing limit", "error", err.Error())
+			response.Error(c, http.StatusBadRequest, "invalid limit")
 			return
 		}
 		req.Limit = int64(limit)
@@ -358,8 +364,8 @@ func (h *Handler) ListAdjustments(c *gin.Context) {
 	} else {
 		page, err := strconv.Atoi(pageStr)
 		if err != nil {
-			h.log.Error("ListAdjustments: error parsing page", "error", err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+			h.log.ErrorContext(c, "ListAdjustments: error parsing page", "error", err.Error())
+			response.Error(c, http.StatusBadRequest, "invalid page")
 			return
 		}
 		req.Page = int64(page)
@@ -367,8 +373,8 @@ func (h *Handler) ListAdjustments(c *gin.Context) {
 
 	res, err := h.UserClient.ListAdjustments(c.Request.Context(), &req)
 	if err != nil {
-		h.log.Error("ListAdjustments: error getting adjustments", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "ListAdjustments: error getting adjustments", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -390,16 +396,16 @@ func (h *Handler) GetWorkerAllInfo(c *gin.Context) {
 	req.Id = c.Param("worker_id")
 	companyID, ok := c.Get("company_id")
 	if !ok {
-		h.log.Error("GetWorkerAllInfo: company_id not found in context")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "company_id not provided"})
+		h.log.ErrorContext(c, "GetWorkerAllInfo: company_id not found in context")
+		response.Error(c, http.StatusBadRequest, "company_id not provided")
 		return
 	}
 	req.CompanyId = companyID.(string)
 
 	res, err := h.UserClient.GetWorkerAllInfo(c.Request.Context(), &req)
 	if err != nil {
-		h.log.Error("GetWorkerAllInfo: error getting worker info", "error", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log.ErrorContext(c, "GetWorkerAllInfo: error getting worker info", "error", err.Error())
+		response.FromGRPC(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
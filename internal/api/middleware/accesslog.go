@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// AccessLogMiddleware logs one line per request with its route template,
+// status, latency, response size and the caller's identity; the request ID
+// is added by the logger from the request context. Successful GETs are
+// logged at the share sample gives their route, falling back to "*", and
+// in full when neither is listed. It must run after RequestIDMiddleware.
+func AccessLogMiddleware(log *slog.Logger, sample map[string]int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		status := c.Writer.Status()
+		if !sampled(sample, c.Request.Method, route, status) {
+			return
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		id, _ := IdentityFrom(c.Request.Context())
+		log.LogAttrs(c.Request.Context(), level, "HTTP request",
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("user_id", id.UserID),
+			slog.String("role", id.Role),
+			slog.String("company_id", id.CompanyID),
+			slog.String("branch_id", id.BranchID),
+		)
+	}
+}
+
+func sampled(sample map[string]int, method, route string, status int) bool {
+	if method != http.MethodGet || status >= http.StatusBadRequest {
+		return true
+	}
+
+	percent, ok := sample[route]
+	if !ok {
+		if percent, ok = sample["*"]; !ok {
+			return true
+		}
+	}
+
+	return rand.IntN(100) < percent
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"gateway/internal/audit"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AuditMiddleware records every POST, PUT, PATCH and DELETE request in the
+// audit trail: who made it, on which record, with what payload and how it
+// ended. Payloads over maxPayload bytes are left out. It must run after
+// PermissionMiddleware, so that only calls the policy let through are
+// recorded.
+func AuditMiddleware(trail *audit.Trail, maxPayload int, log *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+
+		// JSON bodies are read up front and put back for the handler; forms
+		// are parsed by the handler and read afterwards.
+		var body []byte
+		if c.ContentType() == gin.MIMEJSON && c.Request.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(c.Request.Body, int64(maxPayload)+1))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+		}
+
+		writer := &captureWriter{ResponseWriter: c.Writer, limit: maxPayload}
+		c.Writer = writer
+
+		c.Next()
+
+		id, _ := IdentityFrom(c.Request.Context())
+		status := c.Writer.Status()
+		outcome := "success"
+		if status >= http.StatusBadRequest {
+			outcome = "failure"
+		}
+
+		err := trail.Record(audit.Event{
+			ID:         uuid.NewString(),
+			Time:       time.Now().UTC(),
+			RequestID:  id.RequestID,
+			ActorID:    id.UserID,
+			Role:       id.Role,
+			CompanyID:  id.CompanyID,
+			BranchID:   id.BranchID,
+			Method:     c.Request.Method,
+			Route:      c.FullPath(),
+			Path:       c.Request.URL.Path,
+			ResourceID: resourceID(c, writer.body.Bytes()),
+			Payload:    payload(c, body, maxPayload),
+			Status:     status,
+			Outcome:    outcome,
+		})
+		if err != nil {
+			log.ErrorContext(c, "Error recording audit event", "route", c.FullPath(), "error", err.Error())
+		}
+	}
+}
+
+// resourceID is the record a call addressed: the "id" or first "*_id"
+// path parameter, or for creates the "id" of the response.
+func resourceID(c *gin.Context, response []byte) string {
+	for _, p := range c.Params {
+		if p.Key == "id" || strings.HasSuffix(p.Key, "_id") {
+			return p.Value
+		}
+	}
+	if len(c.Params) > 0 {
+		return c.Params[0].Value
+	}
+
+	var created struct {
+		Id string `json:"id"`
+	}
+	json.Unmarshal(response, &created)
+	return created.Id
+}
+
+// payload decodes the JSON body, or collects the form fields with the names
+// of uploaded files in place of their contents.
+func payload(c *gin.Context, body []byte, maxPayload int) any {
+	if len(body) > maxPayload {
+		return map[string]any{"truncated": true}
+	}
+
+	if len(body) > 0 {
+		var v any
+		if json.Unmarshal(body, &v) != nil {
+			return nil
+		}
+		return v
+	}
+
+	if len(c.Request.PostForm) == 0 && c.Request.MultipartForm == nil {
+		return nil
+	}
+
+	form := make(map[string]any, len(c.Request.PostForm))
+	for key, values := range c.Request.PostForm {
+		form[key] = strings.Join(values, ",")
+	}
+	if c.Request.MultipartForm != nil {
+		for key, files := range c.Request.MultipartForm.File {
+			names := make([]string, len(files))
+			for i, f := range files {
+				names[i] = f.Filename
+			}
+			form[key] = strings.Join(names, ",")
+		}
+	}
+	return form
+}
+
+// captureWriter keeps the first limit bytes of the response.
+type captureWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *captureWriter) capture(b []byte) {
+	if room := w.limit - w.body.Len(); room > 0 {
+		w.body.Write(b[:min(len(b), room)])
+	}
+}
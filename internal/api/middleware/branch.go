@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"gateway/internal/api/branch"
+	"gateway/internal/api/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// BranchKey is the gin context key holding the validated branch_id header.
+const BranchKey = "branch_id"
+
+// BranchMiddleware validates the branch_id header against the branches the
+// caller may address and stores it in the context under BranchKey.
+// It must run after PermissionMiddleware, which puts the caller's claims
+// into the context. Requests without the header pass through unchanged;
+// routes that scope their data by branch add RequireBranch.
+func BranchMiddleware(auth *branch.Authorizer) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		branchID := ctx.GetHeader(BranchKey)
+		if branchID == "" {
+			ctx.Next()
+			return
+		}
+
+		allowed, err := auth.Check(ctx.Request.Context(),
+			ctx.GetString("company_id"), ctx.GetString("id"), ctx.GetString("role"), branchID)
+		if err != nil {
+			response.FromGRPC(ctx, err)
+			return
+		}
+
+		if !allowed {
+			response.Error(ctx, http.StatusForbidden, "access to this branch is not allowed")
+			return
+		}
+
+		ctx.Set(BranchKey, branchID)
+		setIdentity(ctx, func(identity *Identity) { identity.BranchID = branchID })
+
+		ctx.Next()
+	}
+}
+
+// RequireBranch rejects requests without a branch from users restricted to
+// some branches of their company, who would otherwise read data of every
+// branch. Owners, admins and unassigned users, when those may address all
+// branches, still get company-wide data without the header. It must run
+// after BranchMiddleware.
+func RequireBranch(auth *branch.Authorizer) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetString(BranchKey) != "" {
+			ctx.Next()
+			return
+		}
+
+		restricted, err := auth.Restricted(ctx.Request.Context(),
+			ctx.GetString("company_id"), ctx.GetString("id"), ctx.GetString("role"))
+		if err != nil {
+			response.FromGRPC(ctx, err)
+			return
+		}
+
+		if restricted {
+			response.Error(ctx, http.StatusBadRequest, "branch_id header is required")
+			return
+		}
+
+		ctx.Next()
+	}
+}
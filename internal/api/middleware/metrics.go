@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"gateway/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+)
+
+// unmatchedRoute labels requests no route serves, so unknown paths cannot
+// blow up the number of series.
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records the count, status and latency of every request
+// by route template, e.g. "/products/:id".
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -3,26 +3,43 @@ package middleware
 import (
 	"errors"
 	"fmt"
+	"gateway/internal/api/response"
 	"gateway/internal/api/token"
+	"gateway/internal/metrics"
+	"gateway/internal/rbac"
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
+// errEnforce marks failures of the policy engine itself rather than of the caller's token.
+var errEnforce = errors.New("error during permission check")
+
 type CasbinPermission struct {
-	enforcer *casbin.Enforcer
+	enforcer *casbin.SyncedEnforcer
+	tokens   *token.Manager
 }
 
 // GetRole extracts and validates the user role from the authorization token.
 func (c *CasbinPermission) GetRole(ctx *gin.Context) (string, error) {
+	claims, err := authenticate(ctx, c.tokens)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.Role, nil
+}
+
+// authenticate validates the access token and puts its claims into the context.
+func authenticate(ctx *gin.Context, tokens *token.Manager) (*token.Claims, error) {
 	tokenStr := ctx.GetHeader("Authorization")
 	if tokenStr == "" {
-		return "", errors.New("missing authorization token")
+		return nil, errors.New("missing authorization token")
 	}
 
-	claims, err := token.ExtractToken(tokenStr, true)
+	claims, err := tokens.ExtractToken(tokenStr, true)
 	if err != nil {
-		return "", fmt.Errorf("invalid token: %w", err)
+		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
 	// Set claims in context for later use
@@ -30,43 +47,71 @@ func (c *CasbinPermission) GetRole(ctx *gin.Context) (string, error) {
 	ctx.Set("role", claims.Role)
 	ctx.Set("company_id", claims.CompanyId)
 	ctx.Set("claims", claims)
+	setIdentity(ctx, func(identity *Identity) {
+		identity.UserID = claims.Id
+		identity.Role = claims.Role
+		identity.CompanyID = claims.CompanyId
+	})
 
-	return claims.Role, nil
+	return claims, nil
 }
 
-// CheckPermission verifies if the user has permission for the requested action.
+// AuthMiddleware only requires a valid access token. It guards routes every
+// signed-in user may call regardless of the policy.
+func AuthMiddleware(tokens *token.Manager) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, err := authenticate(ctx, tokens); err != nil {
+			response.Error(ctx, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// CheckPermission verifies if the user has permission for the requested action
+// within their company.
 func (c *CasbinPermission) CheckPermission(ctx *gin.Context) (bool, error) {
 	role, err := c.GetRole(ctx)
 	if err != nil {
 		return false, err
 	}
 
+	companyID := ctx.GetString("company_id")
+	subject := rbac.Subject(c.enforcer, ctx.GetString("id"), role, companyID)
+
 	action := ctx.Request.Method
 	object := ctx.Request.URL.Path
 
-	allowed, err := c.enforcer.Enforce(role, object, action)
+	allowed, err := c.enforcer.Enforce(subject, companyID, object, action)
 	if err != nil {
-		return false, fmt.Errorf("error during permission check: %w", err)
+		return false, fmt.Errorf("%w: %v", errEnforce, err)
 	}
 
 	return allowed, nil
 }
 
 // PermissionMiddleware creates a Gin middleware for Casbin permission checks.
-func PermissionMiddleware(enforcer *casbin.Enforcer) gin.HandlerFunc {
+func PermissionMiddleware(enforcer *casbin.SyncedEnforcer, tokens *token.Manager) gin.HandlerFunc {
 	permissionHandler := &CasbinPermission{
 		enforcer: enforcer,
+		tokens:   tokens,
 	}
 
 	return func(ctx *gin.Context) {
 		allowed, err := permissionHandler.CheckPermission(ctx)
+		if errors.Is(err, errEnforce) {
+			response.Error(ctx, http.StatusInternalServerError, "internal server error")
+			return
+		}
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			response.Error(ctx, http.StatusUnauthorized, err.Error())
 			return
 		}
 
 		if !allowed {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "permission denied"})
+			metrics.CasbinDenials.WithLabelValues("policy", ctx.Request.Method, ctx.FullPath()).Inc()
+			response.Error(ctx, http.StatusForbidden, "permission denied")
 			return
 		}
 
@@ -79,7 +124,8 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, Branch-Id, branch_id")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID, Branch-Id, branch_id")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Max-Age", "3600")
 
 		if c.Request.Method == "OPTIONS" {
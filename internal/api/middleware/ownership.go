@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"gateway/internal/api/response"
+	"gateway/internal/metrics"
+	"gateway/internal/rbac"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// RecordLoader fetches the record a request addresses. Errors are expected
+// to come from a backend and are mapped with response.FromGRPC.
+type RecordLoader func(ctx *gin.Context) (rbac.Record, error)
+
+// OwnershipMiddleware applies the ownership rules to a single-record route.
+// It must run after PermissionMiddleware and BranchMiddleware.
+func OwnershipMiddleware(ownership *rbac.Ownership, load RecordLoader) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		record, err := load(ctx)
+		if err != nil {
+			response.FromGRPC(ctx, err)
+			return
+		}
+
+		caller := rbac.Caller{
+			Id:        ctx.GetString("id"),
+			Role:      ctx.GetString("role"),
+			CompanyId: ctx.GetString("company_id"),
+		}
+
+		allowed, err := ownership.Allowed(caller, record, ctx.Request.Method)
+		if err != nil {
+			response.Error(ctx, http.StatusInternalServerError, "internal server error")
+			return
+		}
+
+		if !allowed {
+			metrics.CasbinDenials.WithLabelValues("ownership", ctx.Request.Method, ctx.FullPath()).Inc()
+			response.Error(ctx, http.StatusForbidden, "you are not allowed to change this record")
+			return
+		}
+
+		ctx.Next()
+	}
+}
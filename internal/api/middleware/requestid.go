@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"gateway/internal/api/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"log/slog"
+	"regexp"
+)
+
+// validRequestID limits accepted X-Request-ID values to safe characters, so
+// they can be logged and forwarded as they are.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// Identity is who a request is served for, as far as it is known.
+type Identity struct {
+	RequestID string
+	UserID    string
+	Role      string
+	CompanyID string
+	BranchID  string
+}
+
+// identityKey is the request context key holding the Identity.
+type identityKey struct{}
+
+// RequestIDMiddleware accepts the caller's X-Request-ID or assigns a new
+// one and echoes it in the response. It starts the Identity of the request
+// in c.Request.Context(), which the later middlewares fill in.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(response.RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(response.RequestIDKey, id)
+		c.Header(response.RequestIDHeader, id)
+		setIdentity(c, func(identity *Identity) { identity.RequestID = id })
+
+		c.Next()
+	}
+}
+
+// setIdentity replaces the Identity in the request context with a copy
+// changed by update. The Identity is a value, so contexts derived earlier
+// keep what they saw.
+func setIdentity(c *gin.Context, update func(*Identity)) {
+	identity, _ := IdentityFrom(c.Request.Context())
+	update(&identity)
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), identityKey{}, identity))
+}
+
+// IdentityFrom returns the identity of the request ctx belongs to. It
+// reports false for contexts that do not belong to a request.
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// MetadataInterceptor sends the identity of the request as outgoing
+// metadata (request_id, user_id, role, company_id, branch_id) on every
+// backend call. Empty values are left out.
+func MetadataInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id, ok := IdentityFrom(ctx); ok {
+			var kv []string
+			for _, field := range [][2]string{
+				{"request_id", id.RequestID},
+				{"user_id", id.UserID},
+				{"role", id.Role},
+				{"company_id", id.CompanyID},
+				{"branch_id", id.BranchID},
+			} {
+				if field[1] != "" {
+					kv = append(kv, field[0], field[1])
+				}
+			}
+			ctx = metadata.AppendToOutgoingContext(ctx, kv...)
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// LogAttrs returns the request ID of ctx as a log attribute, for
+// logger.NewContextHandler.
+func LogAttrs(ctx context.Context) []slog.Attr {
+	id, ok := IdentityFrom(ctx)
+	if !ok || id.RequestID == "" {
+		return nil
+	}
+	return []slog.Attr{slog.String("request_id", id.RequestID)}
+}
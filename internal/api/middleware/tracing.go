@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"gateway/internal/api/response"
+	"gateway/internal/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// TracingMiddleware starts a server span per request, continuing the trace
+// of the caller's traceparent header if there is one. The spans of backend
+// calls and uploads made while serving the request are its children. It
+// must run after RequestIDMiddleware.
+func TracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		ctx, span := tracing.Tracer().Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				attribute.String("request_id", c.GetString(response.RequestIDKey)),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if id, ok := IdentityFrom(c.Request.Context()); ok {
+			span.SetAttributes(
+				attribute.String("user_id", id.UserID),
+				attribute.String("company_id", id.CompanyID),
+				attribute.String("branch_id", id.BranchID),
+			)
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
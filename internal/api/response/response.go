@@ -0,0 +1,167 @@
+package response
+
+import (
+	"net/http"
+	"regexp"
+
+	"gateway/internal/entity"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDKey is the gin context key holding the current request ID.
+const RequestIDKey = "request_id"
+
+// RequestIDHeader is the header a request ID is read from and echoed in.
+const RequestIDHeader = "X-Request-ID"
+
+// grpcMapping describes how a backend status code is presented to clients.
+type grpcMapping struct {
+	status  int
+	code    string
+	message string
+}
+
+// grpcStatuses maps gRPC codes to HTTP statuses and client-safe messages.
+// Backend error text is never copied into the response body.
+var grpcStatuses = map[codes.Code]grpcMapping{
+	codes.InvalidArgument:    {http.StatusBadRequest, "INVALID_ARGUMENT", "invalid argument"},
+	codes.OutOfRange:         {http.StatusBadRequest, "OUT_OF_RANGE", "value out of range"},
+	codes.FailedPrecondition: {http.StatusBadRequest, "FAILED_PRECONDITION", "operation is not allowed in the current state"},
+	codes.NotFound:           {http.StatusNotFound, "NOT_FOUND", "resource not found"},
+	codes.AlreadyExists:      {http.StatusConflict, "ALREADY_EXISTS", "resource already exists"},
+	codes.Aborted:            {http.StatusConflict, "ABORTED", "operation was aborted, please retry"},
+	codes.PermissionDenied:   {http.StatusForbidden, "PERMISSION_DENIED", "permission denied"},
+	codes.Unauthenticated:    {http.StatusUnauthorized, "UNAUTHENTICATED", "unauthenticated"},
+	codes.ResourceExhausted:  {http.StatusTooManyRequests, "RESOURCE_EXHAUSTED", "too many requests"},
+	codes.Unimplemented:      {http.StatusNotImplemented, "UNIMPLEMENTED", "not implemented"},
+	codes.Unavailable:        {http.StatusServiceUnavailable, "UNAVAILABLE", "service temporarily unavailable"},
+	codes.DeadlineExceeded:   {http.StatusGatewayTimeout, "DEADLINE_EXCEEDED", "backend did not respond in time"},
+	codes.Canceled:           {http.StatusRequestTimeout, "CANCELLED", "request was canceled"},
+}
+
+// httpCodes gives the machine-readable code for statuses produced by the gateway itself.
+var httpCodes = map[int]string{
+	http.StatusBadRequest:          "INVALID_ARGUMENT",
+	http.StatusUnauthorized:        "UNAUTHENTICATED",
+	http.StatusForbidden:           "PERMISSION_DENIED",
+	http.StatusNotFound:            "NOT_FOUND",
+	http.StatusRequestTimeout:      "CANCELLED",
+	http.StatusConflict:            "ALREADY_EXISTS",
+	http.StatusTooManyRequests:     "RESOURCE_EXHAUSTED",
+	http.StatusInternalServerError: "INTERNAL",
+	http.StatusNotImplemented:      "UNIMPLEMENTED",
+	http.StatusServiceUnavailable:  "UNAVAILABLE",
+	http.StatusGatewayTimeout:      "DEADLINE_EXCEEDED",
+}
+
+// Error aborts the request with the uniform error envelope.
+func Error(c *gin.Context, status int, message string, details ...interface{}) {
+	write(c, status, Code(status), message, details)
+}
+
+// Invalid aborts a request whose body or query failed to bind. Broken field
+// rules are listed in the details as entity.FieldError under the code
+// VALIDATION_FAILED; input that could not be decoded at all is a plain 400.
+func Invalid(c *gin.Context, err error) {
+	fields := entity.FieldErrors(err)
+	if len(fields) == 0 {
+		Error(c, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	details := make([]interface{}, len(fields))
+	for i, f := range fields {
+		details[i] = f
+	}
+	write(c, http.StatusBadRequest, "VALIDATION_FAILED", "request validation failed", details)
+}
+
+// FromGRPC translates an error returned by a backend client into the
+// matching HTTP status. Errors that do not carry a gRPC status, and
+// codes without a dedicated mapping, become a generic 500.
+func FromGRPC(c *gin.Context, err error) {
+	st, _ := status.FromError(err)
+
+	m, ok := grpcStatuses[st.Code()]
+	if !ok {
+		Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	write(c, m.status, m.code, m.message, Details(st))
+}
+
+func write(c *gin.Context, status int, code, message string, details []interface{}) {
+	c.AbortWithStatusJSON(status, entity.Error{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestId: RequestID(c),
+	})
+}
+
+// Backend detail values are only passed on when they look like the
+// identifiers they are meant to be, so free text never reaches clients.
+var (
+	detailField  = regexp.MustCompile(`^[A-Za-z0-9_.\[\]]{1,64}$`)
+	detailReason = regexp.MustCompile(`^[A-Z][A-Z0-9_]{0,63}$`)
+	detailName   = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,128}$`)
+)
+
+// Details extracts a whitelist of the well-known status details: the
+// fields of a BadRequest as entity.FieldError, the types of failed
+// preconditions, the resource type and name, the ErrorInfo reason and the
+// retry delay. Descriptions, localized messages, domains and unknown detail
+// types are dropped because they may carry internal data.
+func Details(st *status.Status) []interface{} {
+	var out []interface{}
+	for _, d := range st.Details() {
+		switch v := d.(type) {
+		case *errdetails.BadRequest:
+			for _, fv := range v.GetFieldViolations() {
+				if detailField.MatchString(fv.GetField()) {
+					out = append(out, entity.FieldError{Field: fv.GetField(), Rule: "backend", Message: "is invalid"})
+				}
+			}
+		case *errdetails.PreconditionFailure:
+			for _, pv := range v.GetViolations() {
+				if detailReason.MatchString(pv.GetType()) {
+					out = append(out, gin.H{"type": pv.GetType()})
+				}
+			}
+		case *errdetails.ResourceInfo:
+			if detailName.MatchString(v.GetResourceType()) && detailName.MatchString(v.GetResourceName()) {
+				out = append(out, gin.H{"resource_type": v.GetResourceType(), "resource_name": v.GetResourceName()})
+			}
+		case *errdetails.ErrorInfo:
+			if detailReason.MatchString(v.GetReason()) {
+				out = append(out, gin.H{"reason": v.GetReason()})
+			}
+		case *errdetails.RetryInfo:
+			out = append(out, gin.H{"retry_delay": v.GetRetryDelay().AsDuration().String()})
+		}
+	}
+	return out
+}
+
+// Code returns the machine-readable code for an HTTP status.
+func Code(status int) string {
+	if code, ok := httpCodes[status]; ok {
+		return code
+	}
+	if status >= http.StatusInternalServerError {
+		return "INTERNAL"
+	}
+	return "UNKNOWN"
+}
+
+// RequestID returns the ID of the current request, if one was assigned.
+func RequestID(c *gin.Context) string {
+	if id := c.GetString(RequestIDKey); id != "" {
+		return id
+	}
+	return c.GetHeader(RequestIDHeader)
+}
@@ -5,8 +5,16 @@ import (
 	_ "gateway/internal/api/docs"
 	"gateway/internal/api/handler"
 	"gateway/internal/api/middleware"
-	"github.com/casbin/casbin/v2"
+	"gateway/internal/api/token"
+	"gateway/internal/audit"
+	"gateway/internal/entity"
+	"gateway/internal/health"
+	"gateway/internal/minio"
+	"gateway/internal/rbac"
+	"gateway/pkg"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"log/slog"
@@ -19,21 +27,47 @@ import (
 // @in header
 // @name Authorization
 // @scheme http
-func NewRouter(enf *casbin.Enforcer, cfg *config.Config, log *slog.Logger) *gin.Engine {
-	// Initialize the Gin router
-	router := gin.Default()
+func NewRouter(conns *pkg.Conns, media *minio.Client, checker *health.Checker, policy *rbac.Policy, ownership *rbac.Ownership,
+	trail *audit.Trail, tokens *token.Manager, cfg *config.Config, log *slog.Logger) (*gin.Engine, error) {
+	// Request DTOs are checked against the entity rules while binding.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		entity.RegisterValidations(v)
+	}
 
-	router.Use(middleware.CORSMiddleware())
+	// Initialize the Gin router. Handlers pass their *gin.Context to the
+	// backends, so it falls back to the request context for cancellation,
+	// deadlines and values such as the span and identity of the request.
+	router := gin.New()
+	router.ContextWithFallback = true
+
+	// Recovery runs inside the access log, tracing and metrics so that
+	// panics are recorded as the 500s they turn into.
+	router.Use(
+		middleware.RequestIDMiddleware(),
+		middleware.AccessLogMiddleware(log, cfg.ACCESS_LOG_SAMPLE),
+		middleware.TracingMiddleware(),
+		middleware.MetricsMiddleware(),
+		gin.Recovery(),
+		middleware.CORSMiddleware(),
+	)
 
 	swagger := router.Group("/swagger", gin.BasicAuth(gin.Accounts{
-		"smart-admin": "admin_846", // Логин и пароль
+		cfg.SWAGGER_USER: cfg.SWAGGER_PASSWORD,
 	}))
 	{
 		swagger.GET("/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
 
 	// Initialize the handler with config
-	h := handler.NewHandlerRepo(cfg, log)
+	h, err := handler.NewHandlerRepo(cfg, log, conns, media, checker, policy, ownership, trail, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	router.GET("/.well-known/jwks.json", h.JWKS)
+
+	router.GET("/healthz", h.Healthz)
+	router.GET("/readyz", h.Readyz)
 
 	// User routes group
 	user := router.Group("/user")
@@ -46,12 +80,28 @@ func NewRouter(enf *casbin.Enforcer, cfg *config.Config, log *slog.Logger) *gin.
 		user.PUT("/update/:id", h.UpdateUser)
 		user.DELETE("/delete/:id", h.DeleteUser)
 		user.POST("/get/access-token", h.GetAccessToken)
+		user.POST("/logout", h.Logout)
+		user.POST("/logout/all", h.LogoutAll)
+	}
+
+	// Routes for every signed-in user
+	me := router.Group("/me", middleware.AuthMiddleware(tokens))
+	{
+		me.GET("/permissions", h.GetMyPermissions)
 	}
 
-	router.Use(middleware.PermissionMiddleware(enf))
+	// Everything registered from here on is guarded by the policy.
+	public := router.Routes()
+
+	router.Use(middleware.PermissionMiddleware(policy.Enforcer, tokens))
+	router.Use(middleware.BranchMiddleware(h.Branches))
+	router.Use(middleware.AuditMiddleware(trail, cfg.AUDIT_MAX_PAYLOAD, log))
+
+	// Groups whose data is scoped by branch.
+	requireBranch := middleware.RequireBranch(h.Branches)
 
 	// Product Category routes group
-	pcategory := router.Group("/products/category")
+	pcategory := router.Group("/products/category", requireBranch)
 	{
 		pcategory.POST("", h.CreateCategory)
 		pcategory.GET("", h.GetListCategory)
@@ -61,7 +111,7 @@ func NewRouter(enf *casbin.Enforcer, cfg *config.Config, log *slog.Logger) *gin.
 	}
 
 	// Product routes group
-	products := router.Group("/products")
+	products := router.Group("/products", requireBranch)
 	{
 		products.POST("", h.CreateProduct)
 		products.POST("/bulk/:category_id", h.CreateBulkProducts)
@@ -74,7 +124,7 @@ func NewRouter(enf *casbin.Enforcer, cfg *config.Config, log *slog.Logger) *gin.
 	}
 
 	// Purchase routes group
-	purchase := router.Group("/purchases")
+	purchase := router.Group("/purchases", requireBranch)
 	{
 		purchase.POST("", h.CreatePurchase)
 		purchase.GET("", h.GetListPurchase)
@@ -84,12 +134,12 @@ func NewRouter(enf *casbin.Enforcer, cfg *config.Config, log *slog.Logger) *gin.
 	}
 
 	// Sales routes group
-	sales := router.Group("/sales")
+	sales := router.Group("/sales", requireBranch)
 	{
 		sales.POST("", h.CreateSales)
 		sales.GET("", h.GetListSales)
 		sales.GET("/:id", h.GetSales)
-		sales.PUT("/:id", h.UpdateSales)
+		sales.PUT("/:id", middleware.OwnershipMiddleware(ownership, h.SaleRecord), h.UpdateSales)
 		sales.DELETE("/:id", h.DeleteSales)
 		sales.POST("/calculate", h.CalculateTotalSales)
 	}
@@ -100,7 +150,7 @@ func NewRouter(enf *casbin.Enforcer, cfg *config.Config, log *slog.Logger) *gin.
 		client.POST("", h.CreateClient)
 		client.GET("", h.GetClientList)
 		client.GET("/:id", h.GetClient)
-		client.PUT("/:id", h.UpdateClient)
+		client.PUT("/:id", middleware.OwnershipMiddleware(ownership, h.ClientRecord), h.UpdateClient)
 		client.DELETE("/:id", h.DeleteClient)
 
 	}
@@ -132,6 +182,17 @@ func NewRouter(enf *casbin.Enforcer, cfg *config.Config, log *slog.Logger) *gin.
 		company.PUT("", h.UpdateCompany)
 		company.GET("/users", h.ListCompanyUsers)
 		company.POST("/users", h.CreateCompanyUser)
+		company.GET("/users/:user_id/branches", h.GetUserBranches)
+		company.PUT("/users/:user_id/branches", h.SetUserBranches)
+
+		// roles
+		company.GET("/permissions", h.GetPermissionCatalog)
+		company.GET("/roles", h.ListRoles)
+		company.POST("/roles", h.CreateRole)
+		company.PUT("/roles/:role", h.UpdateRole)
+		company.DELETE("/roles/:role", h.DeleteRole)
+		company.GET("/users/:user_id/roles", h.GetUserRoles)
+		company.PUT("/users/:user_id/roles", h.SetUserRoles)
 
 	}
 
@@ -146,7 +207,7 @@ func NewRouter(enf *casbin.Enforcer, cfg *config.Config, log *slog.Logger) *gin.
 	}
 
 	// Statistics routes group
-	statics := router.Group("/statistics")
+	statics := router.Group("/statistics", requireBranch)
 	{
 		statics.GET("/products/total-price", h.TotalPriceOfProducts)
 		statics.GET("/products/total-sold", h.TotalSoldProducts)
@@ -166,7 +227,7 @@ func NewRouter(enf *casbin.Enforcer, cfg *config.Config, log *slog.Logger) *gin.
 	}
 
 	// CashFlow group
-	cash := router.Group("/cash-flow")
+	cash := router.Group("/cash-flow", requireBranch)
 	{
 		cash.GET("", h.GetCashFlow)
 		cash.POST("/income", h.CreateIncome)
@@ -212,7 +273,7 @@ func NewRouter(enf *casbin.Enforcer, cfg *config.Config, log *slog.Logger) *gin.
 	}
 
 	// Transfers routes group
-	transfers := router.Group("/transfers")
+	transfers := router.Group("/transfers", requireBranch)
 	{
 		transfers.POST("", h.CreateTransfers)
 		transfers.GET("/:id", h.GetTransfers)
@@ -248,5 +309,39 @@ func NewRouter(enf *casbin.Enforcer, cfg *config.Config, log *slog.Logger) *gin.
 		adjustment.GET("", h.ListAdjustments)
 	}
 
-	return router
+	// Policy management, admin only
+	policies := router.Group("/policies")
+	{
+		policies.GET("", h.ListPolicies)
+		policies.POST("", h.AddPolicy)
+		policies.DELETE("", h.RemovePolicy)
+		policies.POST("/reload", h.ReloadPolicies)
+		policies.GET("/explain", h.ExplainPolicy)
+		policies.GET("/versions", h.ListPolicyVersions)
+		policies.POST("/versions/:version/rollback", h.RollbackPolicies)
+	}
+
+	// Audit trail of the caller's company
+	router.GET("/audit", h.ListAudit)
+
+	policy.SetRoutes(guardedRoutes(router, public))
+
+	return router, nil
+}
+
+// guardedRoutes returns the routes of the router that are not public,
+// i.e. that were registered after the permission middleware.
+func guardedRoutes(router *gin.Engine, public gin.RoutesInfo) gin.RoutesInfo {
+	skip := make(map[string]bool, len(public))
+	for _, r := range public {
+		skip[r.Method+" "+r.Path] = true
+	}
+
+	var guarded gin.RoutesInfo
+	for _, r := range router.Routes() {
+		if !skip[r.Method+" "+r.Path] {
+			guarded = append(guarded, r)
+		}
+	}
+	return guarded
 }
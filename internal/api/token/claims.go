@@ -1,9 +1,7 @@
 package token
 
 import (
-	"gateway/config"
 	"github.com/golang-jwt/jwt"
-	"strconv"
 )
 
 type Claims struct {
@@ -12,19 +10,9 @@ type Claims struct {
 	PhoneNumber string `json:"phone_number"`
 	CompanyId   string `json:"company_id"`
 	Role        string `json:"role"`
+	FamilyId    string `json:"fid,omitempty"`
+	// IssuedAtNanos is IssuedAt in nanoseconds, set on the tokens the
+	// gateway issues so that a logout cut-off splits the second it falls in.
+	IssuedAtNanos int64 `json:"iat_ns,omitempty"`
 	jwt.StandardClaims
 }
-
-func ConfigToken(config *config.Config) error {
-
-	exAcc, err := strconv.Atoi(config.EXPIRED_ACCESS)
-	if err != nil {
-		return err
-	}
-
-	AccessSecretKey = config.ACCESS_TOKEN
-	RefreshSecretKey = config.REFRESH_TOKEN
-	ExpiredAccess = exAcc
-
-	return nil
-}
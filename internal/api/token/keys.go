@@ -0,0 +1,342 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// keyReloadInterval is how often the key directory is rescanned for keys
+// added or removed by operators or by another gateway replica.
+const keyReloadInterval = time.Minute
+
+// Key is one signing or verification key of a KeySet.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+	CreatedAt time.Time
+}
+
+// KeySet holds the asymmetric keys used for access tokens. Every "<kid>.pem"
+// file in the directory is one key: a PKCS#8 private key can sign and
+// verify, a PKIX public key only verifies. The newest private key signs.
+type KeySet struct {
+	dir    string
+	method jwt.SigningMethod
+
+	// rotateEvery is the age after which a new signing key is generated;
+	// zero leaves rotation to whoever manages the directory.
+	rotateEvery time.Duration
+	// retireAfter is how long a replaced signing key keeps verifying: the
+	// lifetime of the access tokens it signed.
+	retireAfter time.Duration
+
+	mu      sync.RWMutex
+	keys    map[string]*Key
+	signing *Key
+}
+
+// JWK is the public part of a key in JSON Web Key format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewKeySet loads the keys in dir for the given algorithm ("RS256" or
+// "EdDSA"). A signing key is generated when the directory has none.
+// Replaced keys are pruned once retireAfter has passed.
+func NewKeySet(dir, alg string, rotateEvery, retireAfter time.Duration) (*KeySet, error) {
+	method := jwt.GetSigningMethod(alg)
+	switch method {
+	case jwt.SigningMethodRS256, jwt.SigningMethodEdDSA:
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	s := &KeySet{dir: dir, method: method, rotateEvery: rotateEvery, retireAfter: retireAfter}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	if s.SigningKey() == nil {
+		if err := s.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Reload rereads the key directory.
+func (s *KeySet) Reload() error {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*Key, len(files))
+	var signing *Key
+	for _, file := range files {
+		key, err := s.loadKey(file)
+		if err != nil {
+			return fmt.Errorf("load key %s: %w", file, err)
+		}
+		keys[key.ID] = key
+
+		if key.Private != nil && (signing == nil || key.CreatedAt.After(signing.CreatedAt)) {
+			signing = key
+		}
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.signing = signing
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Rotate generates a new signing key and stores it in the key directory.
+// Older keys stay available for verification until Prune removes them.
+func (s *KeySet) Rotate() error {
+	var private crypto.Signer
+	switch s.method {
+	case jwt.SigningMethodRS256:
+		k, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return err
+		}
+		private = k
+	default:
+		_, k, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return err
+		}
+		private = k
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return err
+	}
+
+	kid := time.Now().UTC().Format("20060102T150405Z")
+	file := filepath.Join(s.dir, kid+".pem")
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return err
+	}
+
+	return s.Reload()
+}
+
+// Prune deletes private keys that stopped signing more than retain ago, so
+// no unexpired token can reference them any more. Public-only keys are
+// managed by operators and are never deleted.
+func (s *KeySet) Prune(retain time.Duration) error {
+	s.mu.RLock()
+	var private []*Key
+	for _, k := range s.keys {
+		if k.Private != nil {
+			private = append(private, k)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(private, func(i, j int) bool { return private[i].CreatedAt.Before(private[j].CreatedAt) })
+
+	removed := false
+	for i := 0; i < len(private)-1; i++ {
+		retiredAt := private[i+1].CreatedAt
+		if time.Since(retiredAt) > retain {
+			if err := os.Remove(filepath.Join(s.dir, private[i].ID+".pem")); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			removed = true
+		}
+	}
+
+	if removed {
+		return s.Reload()
+	}
+	return nil
+}
+
+// Run reloads the key directory periodically and, when rotation is
+// enabled, replaces the signing key once it is older than the rotation
+// interval. It returns when ctx is done.
+func (s *KeySet) Run(ctx context.Context, log *slog.Logger) {
+	ticker := time.NewTicker(keyReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.Reload(); err != nil {
+			log.Error("Error reloading signing keys", "dir", s.dir, "error", err.Error())
+			continue
+		}
+
+		if s.rotateEvery <= 0 {
+			continue
+		}
+
+		if signing := s.SigningKey(); signing == nil || time.Since(signing.CreatedAt) >= s.rotateEvery {
+			if err := s.Rotate(); err != nil {
+				log.Error("Error rotating signing key", "error", err.Error())
+				continue
+			}
+			log.Info("Signing key rotated", "kid", s.SigningKey().ID)
+		}
+
+		if err := s.Prune(s.retireAfter); err != nil {
+			log.Error("Error pruning retired signing keys", "error", err.Error())
+		}
+	}
+}
+
+// SigningKey returns the key new tokens are signed with.
+func (s *KeySet) SigningKey() *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.signing
+}
+
+// VerificationKey returns the key with the given kid.
+func (s *KeySet) VerificationKey(kid string) (*Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	k, ok := s.keys[kid]
+	return k, ok
+}
+
+// JWKS returns the public keys of the set.
+func (s *KeySet) JWKS() JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, k := range s.keys {
+		jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Method.Alg()}
+		switch pub := k.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		out.Keys = append(out.Keys, jwk)
+	}
+
+	sort.Slice(out.Keys, func(i, j int) bool { return out.Keys[i].Kid < out.Keys[j].Kid })
+	return out
+}
+
+func (s *KeySet) loadKey(file string) (*Key, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	key := &Key{
+		ID:        strings.TrimSuffix(filepath.Base(file), ".pem"),
+		Method:    s.method,
+		CreatedAt: info.ModTime(),
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("private key cannot sign")
+		}
+		key.Private = signer
+		key.Public = signer.Public()
+	case "PUBLIC KEY":
+		key.Public, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	if err := s.checkKeyType(key.Public); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (s *KeySet) checkKeyType(pub crypto.PublicKey) error {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		if s.method == jwt.SigningMethodRS256 {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if s.method == jwt.SigningMethodEdDSA {
+			return nil
+		}
+	}
+	return fmt.Errorf("key type %T does not match algorithm %s", pub, s.method.Alg())
+}
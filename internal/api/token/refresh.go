@@ -0,0 +1,148 @@
+package token
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTokenRevoked = errors.New("token has been revoked")
+	ErrTokenReused  = errors.New("refresh token reuse detected, all sessions of this login were revoked")
+)
+
+// IssueRefreshToken starts a new token family for a fresh login.
+func (m *Manager) IssueRefreshToken(in *Claims) (string, error) {
+	return m.generateRefreshToken(in, uuid.NewString())
+}
+
+// RotateRefreshToken consumes a refresh token and returns its claims together
+// with its successor. Presenting an already consumed token revokes the whole
+// family, so a stolen token stops working as soon as either party uses it,
+// unless it comes within ReuseGrace of the first use: concurrent refreshes
+// from one browser then each get a successor. The claims are still returned
+// alongside ErrTokenReused for auditing.
+func (m *Manager) RotateRefreshToken(ctx context.Context, tokenStr string) (*Claims, string, error) {
+	claims, err := m.ExtractToken(tokenStr, false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tokenID, familyID := refreshIDs(tokenStr, claims)
+	expiresAt := time.Unix(claims.ExpiresAt, 0)
+
+	if err := m.checkRefreshUsable(ctx, familyID, claims); err != nil {
+		return nil, "", err
+	}
+
+	usedAt, err := m.revocations.Use(ctx, tokenID, expiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+	if !usedAt.IsZero() && time.Since(usedAt) > m.settings.ReuseGrace {
+		if err := m.revocations.RevokeFamily(ctx, familyID, time.Now().Add(m.settings.RefreshTTL)); err != nil {
+			return nil, "", err
+		}
+		return claims, "", ErrTokenReused
+	}
+
+	next, err := m.generateRefreshToken(claims, familyID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return claims, next, nil
+}
+
+// RevokeRefreshToken ends the login the refresh token belongs to.
+func (m *Manager) RevokeRefreshToken(ctx context.Context, tokenStr string) (*Claims, error) {
+	claims, err := m.ExtractToken(tokenStr, false)
+	if err != nil {
+		return nil, err
+	}
+
+	_, familyID := refreshIDs(tokenStr, claims)
+	if err := m.revocations.RevokeFamily(ctx, familyID, time.Now().Add(m.settings.RefreshTTL)); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// RevokeUser ends every login of the user, including access tokens that
+// were already handed out.
+func (m *Manager) RevokeUser(ctx context.Context, userID string) error {
+	return m.revocations.RevokeUser(ctx, userID, time.Now())
+}
+
+func (m *Manager) generateRefreshToken(in *Claims, familyID string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Id:            in.Id,
+		FirstName:     in.FirstName,
+		PhoneNumber:   in.PhoneNumber,
+		CompanyId:     in.CompanyId,
+		Role:          in.Role,
+		FamilyId:      familyID,
+		IssuedAtNanos: now.UnixNano(),
+		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.NewString(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(m.settings.RefreshTTL).Unix(),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(m.settings.RefreshSecret))
+}
+
+// refreshIDs returns the token and family IDs of a refresh token. Tokens
+// issued by the user service before rotation existed carry neither, so the
+// token hash stands in for both and the token becomes single-use.
+func refreshIDs(tokenStr string, claims *Claims) (string, string) {
+	if claims.StandardClaims.Id != "" && claims.FamilyId != "" {
+		return claims.StandardClaims.Id, claims.FamilyId
+	}
+
+	sum := sha256.Sum256([]byte(tokenStr))
+	id := hex.EncodeToString(sum[:])
+	return id, id
+}
+
+func (m *Manager) checkRefreshUsable(ctx context.Context, familyID string, claims *Claims) error {
+	revoked, err := m.revocations.IsFamilyRevoked(ctx, familyID)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+
+	return m.checkUserRevoked(claims)
+}
+
+func (m *Manager) checkUserRevoked(claims *Claims) error {
+	at, err := m.revocations.UserRevokedAt(context.Background(), claims.Id)
+	if err != nil {
+		return err
+	}
+	if at.IsZero() {
+		return nil
+	}
+
+	// Tokens of the user service only carry whole seconds; those issued in
+	// the second of the cut-off are revoked too.
+	if claims.IssuedAtNanos != 0 {
+		if !time.Unix(0, claims.IssuedAtNanos).After(at) {
+			return ErrTokenRevoked
+		}
+	} else if claims.IssuedAt <= at.Unix() {
+		return ErrTokenRevoked
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package token
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RevocationStore keeps track of consumed refresh tokens, revoked token
+// families and per-user logout cut-offs. Implementations must be safe for
+// concurrent use.
+type RevocationStore interface {
+	// Use marks a refresh token as consumed. It returns the time of the
+	// first use if the token had already been used, the zero time otherwise.
+	Use(ctx context.Context, tokenID string, expiresAt time.Time) (time.Time, error)
+	// RevokeFamily invalidates every refresh token issued from one login.
+	RevokeFamily(ctx context.Context, familyID string, expiresAt time.Time) error
+	// IsFamilyRevoked reports whether the family was revoked.
+	IsFamilyRevoked(ctx context.Context, familyID string) (bool, error)
+	// RevokeUser invalidates every token of the user issued up to at.
+	RevokeUser(ctx context.Context, userID string, at time.Time) error
+	// UserRevokedAt returns the last cut-off set by RevokeUser, or the zero time.
+	UserRevokedAt(ctx context.Context, userID string) (time.Time, error)
+}
+
+// MemoryStore is the default in-process RevocationStore. Its state is lost
+// on restart and is not shared between gateway replicas.
+type MemoryStore struct {
+	// keep is how long a user cut-off is remembered: the lifetime of the
+	// longest-lived token it can still invalidate.
+	keep time.Duration
+
+	mu       sync.Mutex
+	used     map[string]usedToken
+	families map[string]time.Time
+	users    map[string]time.Time
+	lastGC   time.Time
+}
+
+func NewMemoryStore(keep time.Duration) *MemoryStore {
+	return &MemoryStore{
+		keep:     keep,
+		used:     make(map[string]usedToken),
+		families: make(map[string]time.Time),
+		users:    make(map[string]time.Time),
+	}
+}
+
+// usedToken is a consumed refresh token.
+type usedToken struct {
+	usedAt    time.Time
+	expiresAt time.Time
+}
+
+func (s *MemoryStore) Use(_ context.Context, tokenID string, expiresAt time.Time) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gc()
+	if used, ok := s.used[tokenID]; ok {
+		return used.usedAt, nil
+	}
+	s.used[tokenID] = usedToken{usedAt: time.Now(), expiresAt: expiresAt}
+
+	return time.Time{}, nil
+}
+
+func (s *MemoryStore) RevokeFamily(_ context.Context, familyID string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if exp, ok := s.families[familyID]; !ok || exp.Before(expiresAt) {
+		s.families[familyID] = expiresAt
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) IsFamilyRevoked(_ context.Context, familyID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.families[familyID]
+	return ok, nil
+}
+
+func (s *MemoryStore) RevokeUser(_ context.Context, userID string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[userID] = at
+	return nil
+}
+
+func (s *MemoryStore) UserRevokedAt(_ context.Context, userID string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.users[userID], nil
+}
+
+// gc drops entries whose tokens have expired anyway. It runs at most once a
+// minute and must be called with the lock held.
+func (s *MemoryStore) gc() {
+	now := time.Now()
+	if now.Sub(s.lastGC) < time.Minute {
+		return
+	}
+	s.lastGC = now
+
+	for id, used := range s.used {
+		if used.expiresAt.Before(now) {
+			delete(s.used, id)
+		}
+	}
+	for id, exp := range s.families {
+		if exp.Before(now) {
+			delete(s.families, id)
+		}
+	}
+	for id, at := range s.users {
+		if at.Add(s.keep).Before(now) {
+			delete(s.users, id)
+		}
+	}
+}
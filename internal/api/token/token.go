@@ -2,42 +2,97 @@ package token
 
 import (
 	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt"
 	"time"
 )
 
-var (
-	AccessSecretKey  string
-	RefreshSecretKey string
-	ExpiredAccess    int
-)
+// Settings configures how tokens are signed and how long they live.
+type Settings struct {
+	AccessSecret  string
+	RefreshSecret string
+	AccessTTL     time.Duration
+	RefreshTTL    time.Duration
+	// ReuseGrace is how long a consumed refresh token may be presented
+	// again, e.g. by a second tab refreshing at the same time, before the
+	// reuse is taken for theft. Zero allows no reuse.
+	ReuseGrace time.Duration
+	// AcceptHS256 keeps HS256 access tokens valid next to the key set, for
+	// the migration window while such tokens are still in circulation.
+	AcceptHS256 bool
+}
+
+// Manager issues, verifies and revokes the tokens of the gateway.
+type Manager struct {
+	settings Settings
+	// keys signs access tokens when an asymmetric algorithm is configured.
+	// Nil keeps the shared-secret HS256 behaviour.
+	keys        *KeySet
+	revocations RevocationStore
+}
 
-func GenerateAccessToken(in *Claims) (string, error) {
+// NewManager returns a Manager signing with keys, or with the HS256 secrets
+// of settings when keys is nil. revocations backs refresh-token rotation and
+// logout; share it between gateway replicas to share revocation state.
+func NewManager(settings Settings, keys *KeySet, revocations RevocationStore) *Manager {
+	return &Manager{
+		settings:    settings,
+		keys:        keys,
+		revocations: revocations,
+	}
+}
+
+// Keys returns the asymmetric key set, or nil when tokens use HS256.
+func (m *Manager) Keys() *KeySet {
+	return m.keys
+}
+
+func (m *Manager) GenerateAccessToken(in *Claims) (string, error) {
+	now := time.Now()
 	claims := Claims{
-		Id:          in.Id,
-		FirstName:   in.FirstName,
-		PhoneNumber: in.PhoneNumber,
-		CompanyId:   in.CompanyId,
-		Role:        in.Role,
+		Id:            in.Id,
+		FirstName:     in.FirstName,
+		PhoneNumber:   in.PhoneNumber,
+		CompanyId:     in.CompanyId,
+		Role:          in.Role,
+		IssuedAtNanos: now.UnixNano(),
 		StandardClaims: jwt.StandardClaims{
-			IssuedAt:  time.Now().Unix(),
-			ExpiresAt: time.Now().Add(time.Hour * time.Duration(ExpiredAccess)).Unix(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(m.settings.AccessTTL).Unix(),
 		},
 	}
+
+	if m.keys != nil {
+		key := m.keys.SigningKey()
+		token := jwt.NewWithClaims(key.Method, claims)
+		token.Header["kid"] = key.ID
+		return token.SignedString(key.Private)
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(AccessSecretKey))
+	return token.SignedString([]byte(m.settings.AccessSecret))
 }
 
-func ExtractToken(tokenStr string, isAccessToken bool) (*Claims, error) {
+func (m *Manager) ExtractToken(tokenStr string, isAccessToken bool) (*Claims, error) {
 	var secretKey string
 	if isAccessToken {
-		secretKey = AccessSecretKey
+		secretKey = m.settings.AccessSecret
 	} else {
-		secretKey = RefreshSecretKey
+		secretKey = m.settings.RefreshSecret
 	}
 
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secretKey), nil
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			if isAccessToken && m.keys != nil && !m.settings.AcceptHS256 {
+				return nil, errors.New("HS256 access tokens are no longer accepted")
+			}
+			return []byte(secretKey), nil
+		}
+
+		if !isAccessToken || m.keys == nil {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return m.verificationKey(token)
 	})
 	if err != nil {
 
@@ -53,9 +108,30 @@ func ExtractToken(tokenStr string, isAccessToken bool) (*Claims, error) {
 		return nil, errors.New("token has expired")
 	}
 
+	if isAccessToken {
+		if err := m.checkUserRevoked(claims); err != nil {
+			return nil, err
+		}
+	}
+
 	return claims, nil
 }
 
-func GetExpires() int {
-	return ExpiredAccess
+// verificationKey resolves the public key named by the token's kid header.
+func (m *Manager) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := m.keys.VerificationKey(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if key.Method.Alg() != token.Method.Alg() {
+		return nil, fmt.Errorf("signing method %s does not match key %q", token.Method.Alg(), kid)
+	}
+
+	return key.Public, nil
+}
+
+// RefreshTTL returns the lifetime of refresh tokens issued by the gateway.
+func (m *Manager) RefreshTTL() time.Duration {
+	return m.settings.RefreshTTL
 }
@@ -0,0 +1,330 @@
+// Package audit records the mutating API calls of the gateway. Every event
+// is shipped to a Kafka topic and appended to a local journal, from which
+// the recent events of each company are kept in memory for GET /audit. The
+// journal only holds what is needed to restore them: it is compacted to the
+// kept events on start and, in the background, whenever it grows to twice
+// their number.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxJournalLine bounds the size of one journal line read at startup.
+const maxJournalLine = 1 << 20
+
+// Event is one audited API call.
+type Event struct {
+	ID         string    `json:"id"`
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"request_id,omitempty"`
+	ActorID    string    `json:"actor_id"`
+	Role       string    `json:"role"`
+	CompanyID  string    `json:"company_id"`
+	BranchID   string    `json:"branch_id,omitempty"`
+	Method     string    `json:"method" example:"DELETE"`
+	Route      string    `json:"route" example:"/sales/:id"`
+	Path       string    `json:"path" example:"/sales/5f1c7c1e-7d0e-4c59-9a53-0b7f7d0e4c59"`
+	ResourceID string    `json:"resource_id,omitempty"`
+	// Payload is the request body or form, with sensitive fields masked.
+	Payload any    `json:"payload,omitempty" swaggertype:"object"`
+	Status  int    `json:"status" example:"200"`
+	Outcome string `json:"outcome" enums:"success,failure"`
+}
+
+// Filter selects events of one company. Zero fields match everything.
+type Filter struct {
+	ActorID    string
+	Method     string
+	Route      string
+	ResourceID string
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
+
+func (f Filter) match(e Event) bool {
+	return (f.ActorID == "" || e.ActorID == f.ActorID) &&
+		(f.Method == "" || e.Method == f.Method) &&
+		(f.Route == "" || e.Route == f.Route) &&
+		(f.ResourceID == "" || e.ResourceID == f.ResourceID) &&
+		(f.From.IsZero() || !e.Time.Before(f.From)) &&
+		(f.To.IsZero() || e.Time.Before(f.To))
+}
+
+// Trail publishes events and answers queries about recent ones.
+type Trail struct {
+	publisher io.WriteCloser
+	redact    func(any) any
+	keep      int
+	// compactions wakes the background compaction, done stops it.
+	compactions chan struct{}
+	done        chan struct{}
+	wg          sync.WaitGroup
+
+	mu      sync.RWMutex
+	path    string
+	journal *os.File
+	// lines counts the events in the journal, kept those in recent.
+	lines  int
+	kept   int
+	recent map[string][]Event
+	// compacting is set while the background compaction writes the new
+	// journal; the lines recorded meanwhile are collected in pending and
+	// added to it before it replaces the old one.
+	compacting bool
+	pending    [][]byte
+	// compactErr is the error of the last background compaction. The next
+	// Record returns it.
+	compactErr error
+}
+
+// New opens the journal at path and loads the last keep events of every
+// company from it. Events are written to publisher as JSON, one per Write,
+// after redact has masked their payload.
+func New(publisher io.WriteCloser, redact func(any) any, path string, keep int) (*Trail, error) {
+	t := &Trail{
+		publisher:   publisher,
+		redact:      redact,
+		keep:        keep,
+		compactions: make(chan struct{}, 1),
+		done:        make(chan struct{}),
+		path:        path,
+		recent:      make(map[string][]Event),
+	}
+
+	if err := t.load(); err != nil {
+		return nil, err
+	}
+
+	events := t.snapshot()
+	tmp, err := t.writeJournal(events)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.replace(tmp, len(events), nil); err != nil {
+		return nil, err
+	}
+
+	t.wg.Add(1)
+	go t.compactLoop()
+
+	return t, nil
+}
+
+func (t *Trail) load() error {
+	file, err := os.Open(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxJournalLine)
+	for scanner.Scan() {
+		var e Event
+		// A torn last line after a crash is skipped rather than fatal.
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			t.remember(e)
+		}
+	}
+	return scanner.Err()
+}
+
+// Record publishes e and keeps it for Recent. Failures to publish are
+// handled by the publisher, so Record only reports journal errors,
+// including those of a background compaction since the last call.
+func (t *Trail) Record(e Event) error {
+	e.Payload = t.redact(e.Payload)
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	t.publisher.Write(line)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.remember(e)
+	if _, err := t.journal.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	t.lines++
+	if t.compacting {
+		t.pending = append(t.pending, line)
+	} else if t.lines >= 2*t.kept {
+		select {
+		case t.compactions <- struct{}{}:
+		default:
+		}
+	}
+
+	err = t.compactErr
+	t.compactErr = nil
+	return err
+}
+
+// remember must be called with mu held, or before the trail is shared.
+func (t *Trail) remember(e Event) {
+	events := append(t.recent[e.CompanyID], e)
+	if len(events) > t.keep {
+		events = events[len(events)-t.keep:]
+	}
+	t.kept += len(events) - len(t.recent[e.CompanyID])
+	t.recent[e.CompanyID] = events
+}
+
+func (t *Trail) compactLoop() {
+	defer t.wg.Done()
+
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-t.compactions:
+			if err := t.compact(); err != nil {
+				t.mu.Lock()
+				t.compactErr = err
+				t.mu.Unlock()
+			}
+		}
+	}
+}
+
+// compact replaces the journal with one holding only the kept events. The
+// events are written without holding mu, so Record is only blocked while
+// the lines recorded meanwhile are added and the files are swapped.
+func (t *Trail) compact() error {
+	t.mu.Lock()
+	events := t.snapshot()
+	t.compacting = true
+	t.mu.Unlock()
+
+	tmp, err := t.writeJournal(events)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pending := t.pending
+	t.compacting, t.pending = false, nil
+	if err != nil {
+		return err
+	}
+	return t.replace(tmp, len(events), pending)
+}
+
+// snapshot copies the kept events. It must be called with mu held, or
+// before the trail is shared.
+func (t *Trail) snapshot() []Event {
+	events := make([]Event, 0, t.kept)
+	for _, company := range t.recent {
+		events = append(events, company...)
+	}
+	return events
+}
+
+// writeJournal writes events to a new file next to the journal and returns
+// its name.
+func (t *Trail) writeJournal(events []Event) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(t.path), filepath.Base(t.path)+".*")
+	if err != nil {
+		return "", err
+	}
+
+	w := bufio.NewWriter(tmp)
+	for _, e := range events {
+		line, err := json.Marshal(e)
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return "", err
+		}
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := errors.Join(w.Flush(), tmp.Chmod(0644), tmp.Close()); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	return tmp.Name(), nil
+}
+
+// replace appends pending to the new journal tmp holding lines events,
+// renames it over the journal and reopens it for appending. A crash leaves
+// one of the two intact. It must be called with mu held, or before the
+// trail is shared.
+func (t *Trail) replace(tmp string, lines int, pending [][]byte) error {
+	defer os.Remove(tmp)
+
+	if len(pending) > 0 {
+		f, err := os.OpenFile(tmp, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		w := bufio.NewWriter(f)
+		for _, line := range pending {
+			w.Write(line)
+			w.WriteByte('\n')
+		}
+		if err := errors.Join(w.Flush(), f.Close()); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(tmp, t.path); err != nil {
+		return err
+	}
+
+	journal, err := os.OpenFile(t.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if t.journal != nil {
+		t.journal.Close()
+	}
+	t.journal = journal
+	t.lines = lines + len(pending)
+
+	return nil
+}
+
+// Recent returns the events of company that match f, newest first.
+func (t *Trail) Recent(company string, f Filter) []Event {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	events := t.recent[company]
+	found := make([]Event, 0, min(f.Limit, len(events)))
+	for i := len(events) - 1; i >= 0 && len(found) < f.Limit; i-- {
+		if f.match(events[i]) {
+			found = append(found, events[i])
+		}
+	}
+	return found
+}
+
+// Close waits for a running compaction, flushes the publisher and closes
+// the journal.
+func (t *Trail) Close() error {
+	close(t.done)
+	t.wg.Wait()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return errors.Join(t.publisher.Close(), t.journal.Close())
+}
@@ -1,7 +1,5 @@
 package entity
 
-import "gateway/internal/generated/products"
-
 type UserUpdateRequest struct {
 	FirstName   string `json:"first_name"`
 	LastName    string `json:"last_name"`
@@ -11,8 +9,13 @@ type UserUpdateRequest struct {
 	Password    string `json:"password"`
 	CompanyId   string `json:"company_id"`
 }
+
+// Error is the envelope returned by every failed request.
 type Error struct {
-	Message string `json:"message"`
+	Code      string        `json:"code" example:"NOT_FOUND"`
+	Message   string        `json:"message" example:"resource not found"`
+	Details   []interface{} `json:"details,omitempty"`
+	RequestId string        `json:"request_id,omitempty"`
 }
 
 type Names struct {
@@ -28,54 +31,6 @@ type CreateProductRequest struct {
 	Quantity      int64   `json:"quantity" form:"quantity"`
 }
 
-type Sale struct {
-	ClientId      string       `json:"client_id,omitempty"`
-	PaymentMethod string       `json:"payment_method,omitempty"`
-	ClientName    string       `json:"client_name,omitempty"`
-	ClientPhone   string       `json:"client_phone,omitempty"`
-	IsForDebt     bool         `json:"is_for_debt,omitempty" default:"false"`
-	PaidAmount    float64      `json:"paid_amount,omitempty"`
-	SoldProducts  []*SalesItem `json:"sold_products,omitempty"`
-}
-type PaymentSale struct {
-	ClientId      string                `json:"client_id,omitempty"`
-	PaymentMethod string                `json:"payment_method,omitempty"`
-	IsFullyDebt   bool                  `json:"is_fully_debt,omitempty"`
-	CurrencyCode  string                `json:"currency_code,omitempty"`
-	PaidAmount    float64               `json:"paid_amount,omitempty"`
-	BranchId      string                `json:"branch_id,omitempty"`
-	SoldProducts  []*products.SalesItem `json:"sold_products,omitempty"`
-}
-
-type SalesItem struct {
-	Id         string  `json:"id,omitempty"`
-	SaleId     string  `json:"sale_id,omitempty"`
-	ProductId  string  `json:"product_id,omitempty"`
-	Quantity   int32   `json:"quantity,omitempty"`
-	SalePrice  float64 `json:"sale_price,omitempty"`
-	TotalPrice float64 `json:"total_price,omitempty"`
-}
-
-type Purchase struct {
-	SupplierId    string          `json:"supplier_id,omitempty"`
-	Description   string          `json:"description,omitempty"`
-	PaymentMethod string          `json:"payment_method,omitempty"`
-	Items         []*PurchaseItem `json:"items,omitempty"`
-}
-
-type PurchaseItem struct {
-	ProductId     string  `json:"product_id,omitempty"`
-	Quantity      int32   `json:"quantity,omitempty"`
-	PurchasePrice float64 `json:"purchase_price,omitempty"`
-}
-
-type Client struct {
-	FullName   string `json:"full_name"`
-	Address    string `json:"address"`
-	Phone      string `json:"phone"`
-	ClientType string `json:"client_type"`
-}
-
 type CreateCompanyRequest struct {
 	Name    string `json:"name"`
 	Website string `json:"website"`
@@ -125,17 +80,6 @@ type FilterPurchase struct {
 	TotalCost   float64 `json:"total_cost,omitempty"`
 }
 
-type PurchaseUpdate struct {
-	Id            string `json:"id,omitempty"`
-	SupplierId    string `json:"supplier_id,omitempty"`
-	Description   string `json:"description,omitempty"`
-	PaymentMethod string `json:"payment_method,omitempty"`
-}
-
-type SaleUpdate struct {
-	ClientId      string `json:"client_id,omitempty"`
-	PaymentMethod string `json:"payment_method,omitempty"`
-}
 type SaleFilter struct {
 	StartDate string `json:"start_date,omitempty"` // Дата начала для фильтрации
 	EndDate   string `json:"end_date,omitempty"`   // Дата окончания для фильтрации
@@ -168,17 +112,6 @@ type GetTopEntitiesRequest struct {
 	Limit     int32  `json:"limit,omitempty"`
 }
 
-type CreateBulkProductsRequest struct {
-	Products []CreateProductRequestBulk
-}
-type CreateProductRequestBulk struct {
-	Name          string  `json:"name,omitempty"`
-	BillFormat    string  `json:"bill_format,omitempty"`
-	IncomingPrice float64 `json:"incoming_price,omitempty"`
-	StandardPrice float64 `json:"standard_price,omitempty"`
-	TotalCount    int64   `json:"total_count,omitempty"`
-}
-
 type TopClient struct {
 	ID       string  `json:"id,omitempty"`
 	Name     string  `json:"name"`
@@ -206,52 +139,6 @@ type ClientFilter struct {
 	ClientType string `json:"client_type,omitempty"`
 }
 
-type TransferReq struct {
-	ToBranchId  string                  `protobuf:"bytes,3,opt,name=to_branch_id,json=toBranchId,proto3" json:"to_branch_id,omitempty"`
-	Description string                  `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
-	Products    []*TransfersProductsReq `protobuf:"bytes,5,rep,name=products,proto3" json:"products,omitempty"`
-	CompanyId   string                  `protobuf:"bytes,6,opt,name=company_id,json=companyId,proto3" json:"company_id,omitempty"`
-}
-
-type TransfersProductsReq struct {
-	ProductId       string `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
-	ProductQuantity int64  `protobuf:"varint,2,opt,name=product_quantity,json=productQuantity,proto3" json:"product_quantity,omitempty"`
-}
-
-type SalaryRequest struct {
-	UserId       string  `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	CurrencyCode string  `protobuf:"bytes,2,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"`
-	Amount       float64 `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
-	SalaryDate   string  `protobuf:"bytes,4,opt,name=salary_date,json=salaryDate,proto3" json:"salary_date,omitempty"`
-}
-
-type SalaryUpdate struct {
-	CurrencyCode string  `protobuf:"bytes,2,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"`
-	Amount       float64 `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
-	SalaryDate   string  `protobuf:"bytes,4,opt,name=salary_date,json=salaryDate,proto3" json:"salary_date,omitempty"`
-}
-
-type AdjustmentRequest struct {
-	UserId         string  `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	AdjustmentType string  `protobuf:"bytes,2,opt,name=adjustment_type,json=adjustmentType,proto3" json:"adjustment_type,omitempty"`
-	CurrencyCode   string  `protobuf:"bytes,3,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"`
-	Amount         float64 `protobuf:"fixed64,4,opt,name=amount,proto3" json:"amount,omitempty"`
-	AdjustmentDate string  `protobuf:"bytes,5,opt,name=adjustment_date,json=adjustmentDate,proto3" json:"adjustment_date,omitempty"`
-}
-
-type AdjustmentUpdate struct {
-	AdjustmentType string  `protobuf:"bytes,2,opt,name=adjustment_type,json=adjustmentType,proto3" json:"adjustment_type,omitempty"`
-	CurrencyCode   string  `protobuf:"bytes,3,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"`
-	Amount         float64 `protobuf:"fixed64,4,opt,name=amount,proto3" json:"amount,omitempty"`
-	AdjustmentDate string  `protobuf:"bytes,5,opt,name=adjustment_date,json=adjustmentDate,proto3" json:"adjustment_date,omitempty"`
-}
-
-type ClientRequest struct {
-	FullName string `json:"full_name,omitempty"`
-	Address  string `json:"address,omitempty"`
-	Phone    string `json:"phone,omitempty"`
-}
-
 type SupplierFilter struct {
 	FullName string `protobuf:"bytes,1,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
 	Address  string `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
@@ -260,14 +147,26 @@ type SupplierFilter struct {
 	Limit    int32  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
 }
 
-type DebtsRequest struct {
-	ClientId     string  `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
-	TotalAmount  float64 `protobuf:"fixed64,3,opt,name=total_amount,json=totalAmount,proto3" json:"total_amount,omitempty"`
-	CurrencyCode string  `protobuf:"bytes,4,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"`
-	ShouldPayAt  string  `protobuf:"bytes,6,opt,name=should_pay_at,json=shouldPayAt,proto3" json:"should_pay_at,omitempty"`
+type UserBranchesReq struct {
+	BranchIds []string `json:"branch_ids"`
+}
+
+// UserBranches lists the branches a user may work in. Restricted is false
+// only when the user has no assignment and BRANCH_ALLOW_UNASSIGNED lets
+// such users work in every branch.
+type UserBranches struct {
+	UserId     string   `json:"user_id"`
+	BranchIds  []string `json:"branch_ids"`
+	Restricted bool     `json:"restricted"`
+}
+
+type UserRolesReq struct {
+	Roles []string `json:"roles"`
 }
 
-type PayDebtReq struct {
-	DebtId     string  `protobuf:"bytes,1,opt,name=debt_id,json=debtId,proto3" json:"debt_id,omitempty"`
-	PaidAmount float64 `protobuf:"fixed64,3,opt,name=paid_amount,json=paidAmount,proto3" json:"paid_amount,omitempty"`
+// UserRoles lists the company roles of a user. An empty list means the user
+// is authorized by the role in their token.
+type UserRoles struct {
+	UserId string   `json:"user_id"`
+	Roles  []string `json:"roles"`
 }
@@ -0,0 +1,347 @@
+package entity
+
+import (
+	"time"
+
+	"gateway/internal/generated/debts"
+	"gateway/internal/generated/products"
+	"gateway/internal/generated/user"
+)
+
+// Request bodies and queries are bound into the types below rather than
+// into the generated messages, so that the binding tags (see
+// RegisterValidations) reject bad input before it reaches a backend. Fields
+// the gateway fills in itself, such as company and author ids, are left out.
+
+type Sale struct {
+	ClientId      string       `json:"client_id,omitempty" binding:"omitempty,uuid"`
+	PaymentMethod string       `json:"payment_method,omitempty" binding:"omitempty,payment_method" enums:"uzs,usd,card"`
+	ClientName    string       `json:"client_name,omitempty"`
+	ClientPhone   string       `json:"client_phone,omitempty"`
+	IsForDebt     bool         `json:"is_for_debt,omitempty" default:"false"`
+	PaidAmount    float64      `json:"paid_amount,omitempty" binding:"gte=0"`
+	SoldProducts  []*SalesItem `json:"sold_products,omitempty" binding:"required,min=1,dive,required"`
+}
+
+func (s *Sale) Proto() *products.SaleRequest {
+	return &products.SaleRequest{
+		ClientId:      s.ClientId,
+		PaymentMethod: s.PaymentMethod,
+		ClientName:    s.ClientName,
+		ClientPhone:   s.ClientPhone,
+		IsForDebt:     s.IsForDebt,
+		PaidAmount:    s.PaidAmount,
+		SoldProducts:  salesItems(s.SoldProducts),
+	}
+}
+
+// PaymentSale is a sale paid in cash, or taken on debt with PaidAmount paid
+// up front unless IsFullyDebt.
+type PaymentSale struct {
+	ClientId      string       `json:"client_id,omitempty" binding:"required,uuid"`
+	PaymentMethod string       `json:"payment_method,omitempty" binding:"required,oneof=cash debt" enums:"cash,debt"`
+	IsFullyDebt   bool         `json:"is_fully_debt,omitempty"`
+	CurrencyCode  string       `json:"currency_code,omitempty" binding:"required_if=PaymentMethod debt,omitempty,currency" enums:"uzs,usd"`
+	PaidAmount    float64      `json:"paid_amount,omitempty" binding:"gte=0"`
+	BranchId      string       `json:"branch_id,omitempty" binding:"omitempty,uuid"`
+	SoldProducts  []*SalesItem `json:"sold_products,omitempty" binding:"required,min=1,dive,required"`
+}
+
+func (p *PaymentSale) Proto() *products.SaleRequest {
+	return &products.SaleRequest{
+		ClientId:      p.ClientId,
+		PaymentMethod: p.PaymentMethod,
+		BranchId:      p.BranchId,
+		SoldProducts:  salesItems(p.SoldProducts),
+	}
+}
+
+type SalesItem struct {
+	ProductId string  `json:"product_id,omitempty" binding:"required,uuid"`
+	Quantity  int32   `json:"quantity,omitempty" binding:"gt=0"`
+	SalePrice float64 `json:"sale_price,omitempty" binding:"gte=0"`
+}
+
+func salesItems(items []*SalesItem) []*products.SalesItem {
+	out := make([]*products.SalesItem, len(items))
+	for i, item := range items {
+		out[i] = &products.SalesItem{
+			ProductId: item.ProductId,
+			Quantity:  item.Quantity,
+			SalePrice: item.SalePrice,
+		}
+	}
+	return out
+}
+
+type SaleUpdate struct {
+	ClientId      string `json:"client_id,omitempty" binding:"omitempty,uuid"`
+	PaymentMethod string `json:"payment_method,omitempty" binding:"omitempty,payment_method" enums:"uzs,usd,card"`
+}
+
+func (s *SaleUpdate) Proto() *products.SaleUpdate {
+	return &products.SaleUpdate{
+		ClientId:      s.ClientId,
+		PaymentMethod: s.PaymentMethod,
+	}
+}
+
+type Purchase struct {
+	SupplierId    string          `json:"supplier_id,omitempty" binding:"required,uuid"`
+	Description   string          `json:"description,omitempty"`
+	PaymentMethod string          `json:"payment_method,omitempty" binding:"required,payment_method" enums:"uzs,usd,card"`
+	Items         []*PurchaseItem `json:"items,omitempty" binding:"required,min=1,dive,required"`
+}
+
+func (p *Purchase) Proto() *products.PurchaseRequest {
+	items := make([]*products.PurchaseItem, len(p.Items))
+	for i, item := range p.Items {
+		items[i] = &products.PurchaseItem{
+			ProductId:     item.ProductId,
+			Quantity:      item.Quantity,
+			PurchasePrice: item.PurchasePrice,
+		}
+	}
+
+	return &products.PurchaseRequest{
+		SupplierId:    p.SupplierId,
+		Description:   p.Description,
+		PaymentMethod: p.PaymentMethod,
+		Items:         items,
+	}
+}
+
+type PurchaseItem struct {
+	ProductId     string  `json:"product_id,omitempty" binding:"required,uuid"`
+	Quantity      int32   `json:"quantity,omitempty" binding:"gt=0"`
+	PurchasePrice float64 `json:"purchase_price,omitempty" binding:"gt=0"`
+}
+
+type PurchaseUpdate struct {
+	SupplierId    string `json:"supplier_id,omitempty" binding:"omitempty,uuid"`
+	Description   string `json:"description,omitempty"`
+	PaymentMethod string `json:"payment_method,omitempty" binding:"omitempty,payment_method" enums:"uzs,usd,card"`
+}
+
+func (p *PurchaseUpdate) Proto() *products.PurchaseUpdate {
+	return &products.PurchaseUpdate{
+		SupplierId:    p.SupplierId,
+		Description:   p.Description,
+		PaymentMethod: p.PaymentMethod,
+	}
+}
+
+// CreateBulkProductsRequest is the body of POST /products/bulk/{category_id}.
+type CreateBulkProductsRequest struct {
+	Products []*CreateProductRequestBulk `json:"products,omitempty" binding:"required,min=1,dive,required"`
+}
+
+func (r *CreateBulkProductsRequest) Proto() *products.CreateBulkProductsRequest {
+	items := make([]*products.CreateProductRequestBulk, len(r.Products))
+	for i, item := range r.Products {
+		items[i] = &products.CreateProductRequestBulk{
+			Name:          item.Name,
+			BillFormat:    item.BillFormat,
+			IncomingPrice: item.IncomingPrice,
+			StandardPrice: item.StandardPrice,
+			TotalCount:    item.TotalCount,
+		}
+	}
+
+	return &products.CreateBulkProductsRequest{Products: items}
+}
+
+type CreateProductRequestBulk struct {
+	Name          string  `json:"name,omitempty" binding:"required"`
+	BillFormat    string  `json:"bill_format,omitempty"`
+	IncomingPrice float64 `json:"incoming_price,omitempty" binding:"gte=0"`
+	StandardPrice float64 `json:"standard_price,omitempty" binding:"gte=0"`
+	TotalCount    int64   `json:"total_count,omitempty" binding:"gte=0"`
+}
+
+// CategoryPath is the category_id path parameter.
+type CategoryPath struct {
+	CategoryId string `uri:"category_id" binding:"required,uuid"`
+}
+
+type TransferReq struct {
+	ToBranchId  string                  `json:"to_branch_id,omitempty" binding:"required,uuid"`
+	Description string                  `json:"description,omitempty"`
+	Products    []*TransfersProductsReq `json:"products,omitempty" binding:"required,min=1,dive,required"`
+}
+
+func (t *TransferReq) Proto() *products.TransferReq {
+	items := make([]*products.TransfersProductsReq, len(t.Products))
+	for i, item := range t.Products {
+		items[i] = &products.TransfersProductsReq{
+			ProductId:       item.ProductId,
+			ProductQuantity: item.ProductQuantity,
+		}
+	}
+
+	return &products.TransferReq{
+		ToBranchId:  t.ToBranchId,
+		Description: t.Description,
+		Products:    items,
+	}
+}
+
+type TransfersProductsReq struct {
+	ProductId       string `json:"product_id,omitempty" binding:"required,uuid"`
+	ProductQuantity int64  `json:"product_quantity,omitempty" binding:"gt=0"`
+}
+
+type CashFlowRequest struct {
+	Amount        float64 `json:"amount,omitempty" binding:"gt=0"`
+	Description   string  `json:"description,omitempty"`
+	PaymentMethod string  `json:"payment_method,omitempty" binding:"required,payment_method" enums:"uzs,usd,card"`
+}
+
+func (r *CashFlowRequest) Proto() *products.CashFlowRequest {
+	return &products.CashFlowRequest{
+		Amount:        r.Amount,
+		Description:   r.Description,
+		PaymentMethod: r.PaymentMethod,
+	}
+}
+
+// ClientRequest is a new client or supplier.
+type ClientRequest struct {
+	FullName string `json:"full_name,omitempty" binding:"required"`
+	Address  string `json:"address,omitempty"`
+	Phone    string `json:"phone,omitempty" binding:"required,phone" example:"+998901234567"`
+}
+
+func (r *ClientRequest) Proto() *user.ClientRequest {
+	return &user.ClientRequest{
+		FullName: r.FullName,
+		Address:  r.Address,
+		Phone:    r.Phone,
+	}
+}
+
+// ClientUpdate changes the fields of a client or supplier that are set.
+type ClientUpdate struct {
+	FullName string `json:"full_name,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Phone    string `json:"phone,omitempty" binding:"omitempty,phone" example:"+998901234567"`
+}
+
+func (r *ClientUpdate) Proto() *user.ClientUpdateRequest {
+	return &user.ClientUpdateRequest{
+		FullName: r.FullName,
+		Address:  r.Address,
+		Phone:    r.Phone,
+	}
+}
+
+type SalaryRequest struct {
+	UserId       string  `json:"user_id,omitempty" binding:"required,uuid"`
+	CurrencyCode string  `json:"currency_code,omitempty" binding:"required,currency" enums:"uzs,usd"`
+	Amount       float64 `json:"amount,omitempty" binding:"gt=0"`
+	SalaryDate   string  `json:"salary_date,omitempty" binding:"omitempty,date" example:"2024-01-31"`
+}
+
+func (r *SalaryRequest) Proto() *user.SalaryRequest {
+	return &user.SalaryRequest{
+		UserId:       r.UserId,
+		CurrencyCode: r.CurrencyCode,
+		Amount:       r.Amount,
+		SalaryDate:   r.SalaryDate,
+	}
+}
+
+type SalaryUpdate struct {
+	CurrencyCode string  `json:"currency_code,omitempty" binding:"omitempty,currency" enums:"uzs,usd"`
+	Amount       float64 `json:"amount,omitempty" binding:"gte=0"`
+	SalaryDate   string  `json:"salary_date,omitempty" binding:"omitempty,date" example:"2024-01-31"`
+}
+
+func (r *SalaryUpdate) Proto() *user.SalaryUpdate {
+	return &user.SalaryUpdate{
+		CurrencyCode: r.CurrencyCode,
+		Amount:       r.Amount,
+		SalaryDate:   r.SalaryDate,
+	}
+}
+
+type AdjustmentRequest struct {
+	UserId         string  `json:"user_id,omitempty" binding:"required,uuid"`
+	AdjustmentType string  `json:"adjustment_type,omitempty" binding:"required,adjustment_type" enums:"bonus,penalty"`
+	CurrencyCode   string  `json:"currency_code,omitempty" binding:"required,currency" enums:"uzs,usd"`
+	Amount         float64 `json:"amount,omitempty" binding:"gt=0"`
+	AdjustmentDate string  `json:"adjustment_date,omitempty" binding:"omitempty,date" example:"2024-01-31"`
+}
+
+func (r *AdjustmentRequest) Proto() *user.AdjustmentRequest {
+	return &user.AdjustmentRequest{
+		UserId:         r.UserId,
+		AdjustmentType: r.AdjustmentType,
+		CurrencyCode:   r.CurrencyCode,
+		Amount:         r.Amount,
+		AdjustmentDate: r.AdjustmentDate,
+	}
+}
+
+type AdjustmentUpdate struct {
+	AdjustmentType string  `json:"adjustment_type,omitempty" binding:"omitempty,adjustment_type" enums:"bonus,penalty"`
+	CurrencyCode   string  `json:"currency_code,omitempty" binding:"omitempty,currency" enums:"uzs,usd"`
+	Amount         float64 `json:"amount,omitempty" binding:"gte=0"`
+	AdjustmentDate string  `json:"adjustment_date,omitempty" binding:"omitempty,date" example:"2024-01-31"`
+}
+
+func (r *AdjustmentUpdate) Proto() *user.AdjustmentUpdate {
+	return &user.AdjustmentUpdate{
+		AdjustmentType: r.AdjustmentType,
+		CurrencyCode:   r.CurrencyCode,
+		Amount:         r.Amount,
+		AdjustmentDate: r.AdjustmentDate,
+	}
+}
+
+type DebtsRequest struct {
+	ClientId     string  `json:"client_id,omitempty" binding:"required,uuid"`
+	TotalAmount  float64 `json:"total_amount,omitempty" binding:"gt=0"`
+	CurrencyCode string  `json:"currency_code,omitempty" binding:"required,currency" enums:"uzs,usd"`
+	ShouldPayAt  string  `json:"should_pay_at,omitempty" binding:"omitempty,date" example:"2024-01-31"`
+}
+
+func (r *DebtsRequest) Proto() *debts.DebtsRequest {
+	return &debts.DebtsRequest{
+		ClientId:     r.ClientId,
+		TotalAmount:  r.TotalAmount,
+		CurrencyCode: r.CurrencyCode,
+		ShouldPayAt:  r.ShouldPayAt,
+	}
+}
+
+type PayDebtReq struct {
+	DebtId     string  `json:"debt_id,omitempty" binding:"required,uuid"`
+	PaidAmount float64 `json:"paid_amount,omitempty" binding:"gt=0"`
+}
+
+func (r *PayDebtReq) Proto() *debts.PayDebtsReq {
+	return &debts.PayDebtsReq{
+		DebtId:     r.DebtId,
+		PaidAmount: r.PaidAmount,
+	}
+}
+
+// DateRange is the required start_date and end_date query of the reports.
+type DateRange struct {
+	StartDate string `form:"start_date" binding:"required,date"`
+	EndDate   string `form:"end_date" binding:"required,date,not_before=start_date"`
+}
+
+// RFC3339 returns the bounds in the format the backends expect.
+func (r *DateRange) RFC3339() (start, end string) {
+	s, _ := ParseDate(r.StartDate)
+	e, _ := ParseDate(r.EndDate)
+	return s.Format(time.RFC3339), e.Format(time.RFC3339)
+}
+
+// DateFilter is an optional start_date and end_date query, passed on as given.
+type DateFilter struct {
+	StartDate string `form:"start_date" binding:"omitempty,date"`
+	EndDate   string `form:"end_date" binding:"omitempty,date,not_before=start_date"`
+}
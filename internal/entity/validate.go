@@ -0,0 +1,236 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// DateLayout is the format of the dates accepted in requests.
+const DateLayout = "2006-01-02"
+
+// Values accepted by the enum rules.
+var (
+	Currencies      = []string{"uzs", "usd"}
+	PaymentMethods  = []string{"uzs", "usd", "card"}
+	AdjustmentTypes = []string{"bonus", "penalty"}
+)
+
+// phoneRe accepts an optional leading '+' followed by digits, possibly
+// grouped with spaces, dashes or parentheses.
+var phoneRe = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{5,18}[0-9]$`)
+
+// FieldError is one rule a request field broke. Field is the JSON (or
+// query) path of the field, Rule the tag of the rule and Param its argument.
+type FieldError struct {
+	Field   string `json:"field" example:"sold_products[0].quantity"`
+	Rule    string `json:"rule" example:"gt"`
+	Param   string `json:"param,omitempty" example:"0"`
+	Message string `json:"message" example:"must be greater than 0"`
+}
+
+// RegisterValidations adds the request rules to v and makes it report fields
+// by their JSON or query name:
+//
+//	currency          one of Currencies
+//	payment_method    one of PaymentMethods
+//	adjustment_type   one of AdjustmentTypes
+//	phone             a phone number, see phoneRe
+//	date              a DateLayout or RFC 3339 date
+//	not_before=field  a date not before the date in the sibling field
+func RegisterValidations(v *validator.Validate) {
+	v.RegisterTagNameFunc(fieldName)
+
+	rules := map[string]validator.Func{
+		"currency":        oneOf(Currencies),
+		"payment_method":  oneOf(PaymentMethods),
+		"adjustment_type": oneOf(AdjustmentTypes),
+		"phone":           validPhone,
+		"date":            validDate,
+		"not_before":      notBefore,
+	}
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ParseDate parses a date in DateLayout or RFC 3339.
+func ParseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(DateLayout, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// fieldName is the name a struct field has in the request.
+func fieldName(f reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(f.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+func oneOf(values []string) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return slices.Contains(values, fl.Field().String())
+	}
+}
+
+func validPhone(fl validator.FieldLevel) bool {
+	return phoneRe.MatchString(fl.Field().String())
+}
+
+func validDate(fl validator.FieldLevel) bool {
+	_, err := ParseDate(fl.Field().String())
+	return err == nil
+}
+
+// notBefore compares the field with the sibling named by the rule's param.
+// Missing or malformed dates pass; required and date report those.
+func notBefore(fl validator.FieldLevel) bool {
+	parent := fl.Parent()
+	for parent.Kind() == reflect.Pointer {
+		parent = parent.Elem()
+	}
+
+	for i := 0; i < parent.NumField(); i++ {
+		if fieldName(parent.Type().Field(i)) != fl.Param() {
+			continue
+		}
+		start, err := ParseDate(parent.Field(i).String())
+		if err != nil {
+			return true
+		}
+		end, err := ParseDate(fl.Field().String())
+		if err != nil {
+			return true
+		}
+		return !end.Before(start)
+	}
+	return true
+}
+
+// FieldErrors describes why a request could not be bound: one entry per
+// broken rule, or per field of the wrong JSON type. It returns nil for
+// errors that are not about a particular field, such as malformed JSON.
+func FieldErrors(err error) []FieldError {
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		fields := make([]FieldError, len(invalid))
+		for i, fe := range invalid {
+			// The namespace starts with the name of the request type.
+			_, path, _ := strings.Cut(fe.Namespace(), ".")
+			fields[i] = FieldError{
+				Field:   path,
+				Rule:    fe.Tag(),
+				Param:   fe.Param(),
+				Message: message(fe),
+			}
+			// Its param names the other field by its Go name.
+			if fe.Tag() == "required_if" {
+				fields[i].Param = ""
+			}
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   jsonPath(typeErr.Field),
+			Rule:    "type",
+			Message: "must be " + jsonType(typeErr.Type),
+		}}
+	}
+
+	return nil
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_if":
+		return "is required"
+	case "uuid":
+		return "must be a UUID"
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be at least " + fe.Param()
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			if fe.Param() == "1" {
+				return "must not be empty"
+			}
+			return fmt.Sprintf("must contain at least %s items", fe.Param())
+		}
+		return "must be at least " + fe.Param()
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "currency":
+		return "must be one of " + strings.Join(Currencies, ", ")
+	case "payment_method":
+		return "must be one of " + strings.Join(PaymentMethods, ", ")
+	case "adjustment_type":
+		return "must be one of " + strings.Join(AdjustmentTypes, ", ")
+	case "phone":
+		return "must be a phone number"
+	case "date":
+		return "must be a date in YYYY-MM-DD format"
+	case "not_before":
+		return "must not be before " + fe.Param()
+	}
+	return "is invalid"
+}
+
+// jsonPath writes the path of a JSON decoding error, like
+// "sold_products.0.quantity", the way validation paths are written:
+// "sold_products[0].quantity".
+func jsonPath(field string) string {
+	parts := strings.Split(field, ".")
+	var b strings.Builder
+	for i, part := range parts {
+		if _, err := strconv.Atoi(part); err == nil {
+			b.WriteString("[" + part + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// jsonType names a Go type the way a JSON client sees it.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
@@ -0,0 +1,142 @@
+// Package health probes the dependencies of the gateway for the readiness
+// endpoint.
+package health
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	// StatusOK means every dependency is up, StatusDegraded that only
+	// non-critical ones are down and StatusUnavailable that a critical one
+	// is down.
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+)
+
+// Probe checks one dependency. Check returns nil when it is usable.
+type Probe struct {
+	Name string
+	// Critical dependencies make the gateway unready when they are down.
+	// It is set by NewChecker.
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+// Result is the outcome of one probe.
+type Result struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of all probes.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Ready reports whether every critical dependency is up.
+func (r Report) Ready() bool {
+	return r.Status != StatusUnavailable
+}
+
+// Checker runs the probes concurrently, each bounded by timeout.
+type Checker struct {
+	probes  []Probe
+	timeout time.Duration
+}
+
+// NewChecker marks the probes named in critical as critical.
+func NewChecker(timeout time.Duration, critical []string, probes ...Probe) *Checker {
+	for i := range probes {
+		probes[i].Critical = slices.Contains(critical, probes[i].Name)
+	}
+	return &Checker{probes: probes, timeout: timeout}
+}
+
+// Check runs every probe and summarises the results.
+func (c *Checker) Check(ctx context.Context) Report {
+	results := make([]Result, len(c.probes))
+
+	var wg sync.WaitGroup
+	for i, probe := range c.probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, probe)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(c.probes))}
+	for i, probe := range c.probes {
+		res := results[i]
+		report.Checks[probe.Name] = res
+
+		if res.Status == StatusDown {
+			if probe.Critical {
+				report.Status = StatusUnavailable
+			} else if report.Status == StatusOK {
+				report.Status = StatusDegraded
+			}
+		}
+	}
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, probe Probe) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := probe.Check(ctx)
+
+	res := Result{
+		Status:    StatusUp,
+		Critical:  probe.Critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+// GRPC probes a backend with the gRPC health protocol for the status of
+// service, e.g. "company.CompanyService". Backends that do not implement
+// the protocol count as up once they answer at all; a service the backend
+// does not know counts as down.
+func GRPC(conn grpc.ClientConnInterface, service string) func(ctx context.Context) error {
+	client := healthpb.NewHealthClient(conn)
+
+	return func(ctx context.Context) error {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if status.Code(err) == codes.Unimplemented {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if res.Status != healthpb.HealthCheckResponse_SERVING {
+			return status.Errorf(codes.Unavailable, "backend reports %s", res.Status)
+		}
+		return nil
+	}
+}
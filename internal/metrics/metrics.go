@@ -0,0 +1,93 @@
+// Package metrics defines the Prometheus metrics of the gateway.
+package metrics
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "gateway"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	GRPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_requests_total",
+		Help:      "Backend RPCs by service, method and status code, retries included.",
+	}, []string{"service", "method", "code"})
+
+	GRPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_request_duration_seconds",
+		Help:      "Backend RPC latency by service and method, retries included.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "method"})
+
+	CasbinDenials = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "casbin_denials_total",
+		Help:      "Requests denied by the route policy (check=\"policy\") or the ownership rules (check=\"ownership\").",
+	}, []string{"check", "method", "route"})
+
+	MinioUploads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "minio_uploads_total",
+		Help:      "Media uploads to MinIO by result.",
+	}, []string{"result"})
+
+	MinioUploadBytes = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "minio_upload_size_bytes",
+		Help:      "Size of media uploaded to MinIO.",
+		Buckets:   prometheus.ExponentialBuckets(16<<10, 4, 8), // 16KiB to 256MiB
+	})
+
+	MinioUploadDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "minio_upload_duration_seconds",
+		Help:      "Duration of media uploads to MinIO.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	KafkaSendFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_send_failures_total",
+		Help:      "Messages that could not be sent to Kafka, by topic.",
+	}, []string{"topic"})
+)
+
+// UnaryClientInterceptor records the count and latency of every backend
+// RPC. The service label is the gRPC service, e.g. "products.Products".
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		service, name := path.Split(method)
+		service = strings.Trim(service, "/")
+
+		GRPCRequests.WithLabelValues(service, name, status.Code(err).String()).Inc()
+		GRPCDuration.WithLabelValues(service, name).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
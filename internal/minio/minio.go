@@ -3,42 +3,73 @@ package minio
 import (
 	"context"
 	"fmt"
+	"gateway/config"
+	"gateway/internal/metrics"
+	"gateway/internal/tracing"
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
-	"log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"mime/multipart"
 	"net/http"
+	"time"
 )
 
-var MinioClient *minio.Client
-var Endpoint = "minio.smartadmin.uz" // Уберите https://
-
-func InitMiniOClient() error {
-	accessKeyID := "minioadmin"
-	secretAccessKey := "minioadmin"
+// Client uploads media to a MinIO bucket.
+type Client struct {
+	client *minio.Client
+	bucket string
+	// baseURL is the public URL of the bucket, without a trailing slash.
+	baseURL string
+}
 
-	minioClient, err := minio.New(Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
-		Secure: true, // Установите true для использования HTTPS
+// NewClient connects to the MinIO server of cfg. The endpoint is a host
+// without a scheme, e.g. "minio.smartadmin.uz".
+func NewClient(cfg *config.Config) (*Client, error) {
+	minioClient, err := minio.New(cfg.MINIO_ENDPOINT, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.MINIO_ACCESS_KEY, cfg.MINIO_SECRET_KEY, ""),
+		Secure: cfg.MINIO_USE_SSL,
 	})
-
 	if err != nil {
-		log.Println(err)
-		return err
+		return nil, err
 	}
 
-	MinioClient = minioClient
+	scheme := "http"
+	if cfg.MINIO_USE_SSL {
+		scheme = "https"
+	}
+
+	return &Client{
+		client:  minioClient,
+		bucket:  cfg.MINIO_BUCKET,
+		baseURL: fmt.Sprintf("%s://%s/%s", scheme, cfg.MINIO_ENDPOINT, cfg.MINIO_BUCKET),
+	}, nil
+}
 
+// Ping checks that the server answers and the bucket exists.
+func (m *Client) Ping(ctx context.Context) error {
+	ok, err := m.client.BucketExists(ctx, m.bucket)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("bucket %q does not exist", m.bucket)
+	}
 	return nil
 }
 
-func UploadMedia(fileHeader *multipart.FileHeader) (string, error) {
+// UploadMedia stores the file under a unique name and returns its public
+// URL. The upload is traced as a child of the span in ctx.
+func (m *Client) UploadMedia(ctx context.Context, fileHeader *multipart.FileHeader) (url string, err error) {
+	ctx, span := tracing.Start(ctx, "minio.PutObject", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("minio.bucket", m.bucket), attribute.Int64("minio.size", fileHeader.Size)))
+	defer func() { tracing.End(span, err) }()
+
 	// Open the uploaded file
 	file, err := fileHeader.Open()
 	if err != nil {
-		log.Println("1-", err)
-		return "", err
+		return "", fmt.Errorf("opening upload: %w", err)
 	}
 	defer file.Close()
 
@@ -48,31 +79,35 @@ func UploadMedia(fileHeader *multipart.FileHeader) (string, error) {
 		buffer := make([]byte, 512) // Read the first 512 bytes to detect the MIME type
 		_, err = file.Read(buffer)
 		if err != nil {
-			log.Println("2-", err)
-			return "", err
+			return "", fmt.Errorf("reading upload: %w", err)
 		}
 		contentType = http.DetectContentType(buffer)
 		// Reset the file pointer to the beginning
 		if _, err = file.Seek(0, 0); err != nil {
-			log.Println("3-", err)
-			return "", err
+			return "", fmt.Errorf("rewinding upload: %w", err)
 		}
 	}
 
 	fileHeader.Filename += uuid.NewString()
+	span.SetAttributes(attribute.String("minio.object", fileHeader.Filename))
 
 	// Upload the file to MinIO
-	_, err = MinioClient.PutObject(context.Background(), "media", fileHeader.Filename, file, fileHeader.Size, minio.PutObjectOptions{
+	start := time.Now()
+	_, err = m.client.PutObject(ctx, m.bucket, fileHeader.Filename, file, fileHeader.Size, minio.PutObjectOptions{
 		ContentType: contentType,
 	})
 
 	if err != nil {
-		log.Println("4-", err)
-		return "", err
+		metrics.MinioUploads.WithLabelValues("error").Inc()
+		return "", fmt.Errorf("storing upload in bucket %s: %w", m.bucket, err)
 	}
 
+	metrics.MinioUploads.WithLabelValues("ok").Inc()
+	metrics.MinioUploadBytes.Observe(float64(fileHeader.Size))
+	metrics.MinioUploadDuration.Observe(time.Since(start).Seconds())
+
 	// Generate the file URL
-	imageUrl := fmt.Sprintf("https://%s/%s/%s", Endpoint, "media", fileHeader.Filename)
+	imageUrl := fmt.Sprintf("%s/%s", m.baseURL, fileHeader.Filename)
 
 	return imageUrl, nil
 }
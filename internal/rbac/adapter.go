@@ -0,0 +1,337 @@
+package rbac
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+)
+
+var _ persist.BatchAdapter = (*Adapter)(nil)
+
+// Adapter loads the built-in policy shipped with the gateway together with
+// the rules created at runtime by company owners and admins. The built-in
+// file is never written; every rule that is not part of it is kept in the
+// store file, one Casbin CSV line per rule. Every write of the store file is
+// recorded in the history when one is set.
+type Adapter struct {
+	builtinPath string
+	storePath   string
+	history     *History
+
+	mu    sync.Mutex
+	rules [][]string
+	// staged holds the rules of a rollback while the enforcer reloads from
+	// them; LoadPolicy uses it instead of the store file.
+	staged [][]string
+	// written is the modification time of the store file after our own
+	// last write, so the watcher can tell it from external edits.
+	written time.Time
+}
+
+// NewAdapter returns an adapter reading built-in rules from builtinPath and
+// keeping runtime rules in storePath. history may be nil.
+func NewAdapter(builtinPath, storePath string, history *History) *Adapter {
+	return &Adapter{builtinPath: builtinPath, storePath: storePath, history: history}
+}
+
+// LoadPolicy loads the built-in and the stored rules into the model.
+func (a *Adapter) LoadPolicy(m model.Model) error {
+	if err := readLines(a.builtinPath, func(line string) error {
+		return loadLine(line, m)
+	}); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	staged := a.staged
+	a.mu.Unlock()
+
+	if staged != nil {
+		for _, rule := range staged {
+			if err := loadLine(strings.Join(rule, ", "), m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var rules [][]string
+	err := readLines(a.storePath, func(line string) error {
+		rules = append(rules, splitLine(line))
+		return loadLine(line, m)
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	a.mu.Lock()
+	a.rules = rules
+	a.mu.Unlock()
+
+	return nil
+}
+
+// SavePolicy stores every rule of the model that is not built in.
+func (a *Adapter) SavePolicy(m model.Model) error {
+	builtin, err := a.Builtin()
+	if err != nil {
+		return err
+	}
+
+	var rules [][]string
+	for _, sec := range []string{"p", "g"} {
+		for ptype, ast := range m[sec] {
+			for _, rule := range ast.Policy {
+				line := append([]string{ptype}, rule...)
+				if !builtin[strings.Join(line, ", ")] {
+					rules = append(rules, line)
+				}
+			}
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rules = rules
+	return a.save("save policy")
+}
+
+func (a *Adapter) AddPolicy(sec string, ptype string, rule []string) error {
+	return a.AddPolicies(sec, ptype, [][]string{rule})
+}
+
+func (a *Adapter) AddPolicies(_ string, ptype string, rules [][]string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, rule := range rules {
+		a.rules = append(a.rules, append([]string{ptype}, rule...))
+	}
+
+	return a.save(describe("add", ptype, rules))
+}
+
+func (a *Adapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	return a.RemovePolicies(sec, ptype, [][]string{rule})
+}
+
+func (a *Adapter) RemovePolicies(_ string, ptype string, rules [][]string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rules = slices.DeleteFunc(a.rules, func(stored []string) bool {
+		for _, rule := range rules {
+			if stored[0] == ptype && slices.Equal(stored[1:], rule) {
+				return true
+			}
+		}
+		return false
+	})
+
+	return a.save(describe("remove", ptype, rules))
+}
+
+func (a *Adapter) RemoveFilteredPolicy(_ string, ptype string, fieldIndex int, fieldValues ...string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rules = slices.DeleteFunc(a.rules, func(stored []string) bool {
+		if stored[0] != ptype {
+			return false
+		}
+		rule := stored[1:]
+		for i, value := range fieldValues {
+			if value == "" {
+				continue
+			}
+			if fieldIndex+i >= len(rule) || rule[fieldIndex+i] != value {
+				return false
+			}
+		}
+		return true
+	})
+
+	return a.save(fmt.Sprintf("remove %s rules matching %v at field %d", ptype, fieldValues, fieldIndex))
+}
+
+// Builtin returns the rules of the built-in policy file as "ptype, v0, v1, ..." lines.
+func (a *Adapter) Builtin() (map[string]bool, error) {
+	builtin := make(map[string]bool)
+	if err := readLines(a.builtinPath, func(line string) error {
+		builtin[strings.Join(splitLine(line), ", ")] = true
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return builtin, nil
+}
+
+// BuiltinDuplicates returns the rules listed more than once in the
+// built-in file, each once, in file order.
+func (a *Adapter) BuiltinDuplicates() ([]Rule, error) {
+	seen := make(map[string]int)
+	var duplicates []Rule
+	if err := readLines(a.builtinPath, func(line string) error {
+		fields := splitLine(line)
+		key := strings.Join(fields, ", ")
+		if seen[key]++; seen[key] == 2 {
+			duplicates = append(duplicates, Rule{Type: fields[0], Rule: fields[1:], Builtin: true})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return duplicates, nil
+}
+
+// Rollback replaces the stored rules with a version from the history.
+// reload must reload the enforcer, which then reads the rules of the
+// version; the store file is only replaced once that succeeded, so a
+// failed reload leaves both on the previous rules.
+func (a *Adapter) Rollback(id int, reload func() error) error {
+	if a.history == nil {
+		return ErrVersionNotFound
+	}
+
+	content, err := a.history.Load(id)
+	if err != nil {
+		return err
+	}
+
+	var rules [][]string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			rules = append(rules, splitLine(line))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if rules == nil {
+		rules = [][]string{}
+	}
+
+	a.mu.Lock()
+	a.staged = rules
+	a.mu.Unlock()
+
+	err = reload()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.staged = nil
+	if err != nil {
+		return err
+	}
+
+	a.rules = rules
+	return a.save(fmt.Sprintf("rollback to version %d", id))
+}
+
+// Versions returns the recorded versions of the stored rules.
+func (a *Adapter) Versions() []Version {
+	if a.history == nil {
+		return []Version{}
+	}
+	return a.history.List()
+}
+
+// save writes the runtime rules atomically and records them in the
+// history. It must be called with the lock held.
+func (a *Adapter) save(reason string) error {
+	var b strings.Builder
+	for _, rule := range a.rules {
+		b.WriteString(strings.Join(rule, ", "))
+		b.WriteString("\n")
+	}
+	content := []byte(b.String())
+
+	if err := writeAtomic(a.storePath, content); err != nil {
+		return err
+	}
+	if info, err := os.Stat(a.storePath); err == nil {
+		a.written = info.ModTime()
+	}
+
+	if a.history != nil {
+		if _, err := a.history.Record(reason, content, len(a.rules)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lastWrite returns the modification time of the store file after the last
+// write made through the adapter.
+func (a *Adapter) lastWrite() time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.written
+}
+
+// loadLine adds one policy line to the model after checking it has as many
+// fields as the model's definition for its type.
+func loadLine(line string, m model.Model) error {
+	fields := splitLine(line)
+	ptype := fields[0]
+	if sec := ptype[:1]; m[sec] != nil {
+		if ast, ok := m[sec][ptype]; ok && len(fields)-1 != len(ast.Tokens) {
+			return fmt.Errorf("policy line %q: %s rules have %d fields", line, ptype, len(ast.Tokens))
+		}
+	}
+
+	return persist.LoadPolicyLine(line, m)
+}
+
+func describe(op, ptype string, rules [][]string) string {
+	if len(rules) == 1 {
+		return fmt.Sprintf("%s %s, %s", op, ptype, strings.Join(rules[0], ", "))
+	}
+	return fmt.Sprintf("%s %d %s rules", op, len(rules), ptype)
+}
+
+// readLines calls fn for every policy line of the file, skipping blank
+// lines and comments.
+func readLines(path string, fn func(line string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func splitLine(line string) []string {
+	fields := strings.Split(line, ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
@@ -0,0 +1,126 @@
+package rbac
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/casbin/casbin/v2/util"
+	"github.com/gin-gonic/gin"
+)
+
+// checkMatcher evaluates built-in roles against a route template. Rules
+// whose object is "/*" grant every route and prove nothing about a single
+// one, so they are left out; routes meant for admins only need their own
+// admin rules.
+const checkMatcher = `g(r.sub, p.sub, r.dom) && p.dom == "*" && p.obj != "/*" && keyMatch2(r.obj, p.obj) && r.act == p.act`
+
+// Report is the result of Check.
+type Report struct {
+	// Unreachable lists guarded routes that no role may call.
+	Unreachable []Permission `json:"unreachable"`
+	// Unused lists built-in domain rules that match no route.
+	Unused []Rule `json:"unused"`
+	// Duplicate lists rules written more than once in the built-in file.
+	Duplicate []Rule `json:"duplicate"`
+	// Access maps "METHOD path" to the roles allowed to call it.
+	Access map[string][]string `json:"access"`
+}
+
+// OK reports whether the policy and the routes are consistent.
+func (r Report) OK() bool {
+	return len(r.Unreachable) == 0 && len(r.Unused) == 0 && len(r.Duplicate) == 0
+}
+
+// String lists the problems of the report, one per line.
+func (r Report) String() string {
+	var b strings.Builder
+	for _, p := range r.Unreachable {
+		fmt.Fprintf(&b, "route %s %s: no role may call it\n", p.Method, p.Path)
+	}
+	for _, rule := range r.Unused {
+		fmt.Fprintf(&b, "rule %s, %s: matches no route\n", rule.Type, strings.Join(rule.Rule, ", "))
+	}
+	for _, rule := range r.Duplicate {
+		fmt.Fprintf(&b, "rule %s, %s: listed more than once\n", rule.Type, strings.Join(rule.Rule, ", "))
+	}
+	return b.String()
+}
+
+// Check evaluates every role of the built-in domain against each guarded
+// route and reports the routes no role can reach, the rules of the
+// built-in domain that match no route and the rules written twice. A rule
+// is only used if its pattern covers a route template; a concrete path
+// such as /clients/street that merely fits a route with parameters is not
+// a route of its own. Company roles are left out: they can only be granted
+// permissions from the catalog.
+func (p *Policy) Check() (Report, error) {
+	report := Report{Access: make(map[string][]string)}
+
+	policies, err := p.Enforcer.GetFilteredPolicy(1, BuiltinDomain)
+	if err != nil {
+		return Report{}, err
+	}
+
+	var roles []string
+	for _, rule := range policies {
+		if !slices.Contains(roles, rule[0]) {
+			roles = append(roles, rule[0])
+		}
+	}
+	sort.Strings(roles)
+
+	routes := p.Routes()
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		report.Access[key] = []string{}
+
+		for _, role := range roles {
+			allowed, err := p.Enforcer.EnforceWithMatcher(checkMatcher, role, BuiltinDomain, route.Path, route.Method)
+			if err != nil {
+				return Report{}, err
+			}
+			if allowed {
+				report.Access[key] = append(report.Access[key], role)
+			}
+		}
+
+		if len(report.Access[key]) == 0 {
+			report.Unreachable = append(report.Unreachable, Permission{Path: route.Path, Method: route.Method})
+		}
+	}
+
+	builtin, err := p.adapter.Builtin()
+	if err != nil {
+		return Report{}, err
+	}
+
+	for _, rule := range policies {
+		obj, act := rule[2], rule[3]
+		if obj == "/*" {
+			continue
+		}
+
+		used := slices.ContainsFunc(routes, func(route gin.RouteInfo) bool {
+			return route.Method == act && util.KeyMatch2(route.Path, obj)
+		})
+		if !used {
+			report.Unused = append(report.Unused, Rule{Type: "p", Rule: rule, Builtin: builtin["p, "+strings.Join(rule, ", ")]})
+		}
+	}
+
+	report.Duplicate, err = p.adapter.BuiltinDuplicates()
+	if err != nil {
+		return Report{}, err
+	}
+
+	sort.Slice(report.Unreachable, func(i, j int) bool {
+		if report.Unreachable[i].Path != report.Unreachable[j].Path {
+			return report.Unreachable[i].Path < report.Unreachable[j].Path
+		}
+		return report.Unreachable[i].Method < report.Unreachable[j].Method
+	})
+
+	return report, nil
+}
@@ -0,0 +1,128 @@
+package rbac
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/casbin/casbin/v2/util"
+)
+
+// Explanation tells why the policy allows or denies a request.
+type Explanation struct {
+	Allowed bool   `json:"allowed"`
+	Subject string `json:"subject"`
+	Domain  string `json:"domain"`
+	// Roles are the roles the subject holds in the domain, directly or
+	// through inheritance.
+	Roles []string `json:"roles"`
+	// Matched is the rule that allowed the request.
+	Matched *Rule `json:"matched,omitempty"`
+	// Route is the route template serving the request, if any.
+	Route  string `json:"route,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Allowed returns the guarded routes the caller may call, grouped by
+// resource, i.e. the first segment of the route path.
+func (p *Policy) Allowed(userID, role, companyID string) (map[string][]Permission, error) {
+	subject := Subject(p.Enforcer, userID, role, companyID)
+
+	allowed := make(map[string][]Permission)
+	for _, r := range p.Routes() {
+		ok, err := p.Enforcer.Enforce(subject, companyID, r.Path, r.Method)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			resource := Resource(r.Path)
+			allowed[resource] = append(allowed[resource], Permission{Path: r.Path, Method: r.Method})
+		}
+	}
+
+	for _, perms := range allowed {
+		sort.Slice(perms, func(i, j int) bool {
+			if perms[i].Path != perms[j].Path {
+				return perms[i].Path < perms[j].Path
+			}
+			return perms[i].Method < perms[j].Method
+		})
+	}
+
+	return allowed, nil
+}
+
+// Explain evaluates a request the way PermissionMiddleware does and
+// reports the rule that allowed it or the reason it was denied.
+func (p *Policy) Explain(userID, role, companyID, method, path string) (Explanation, error) {
+	method = strings.ToUpper(method)
+	subject := Subject(p.Enforcer, userID, role, companyID)
+
+	roles, err := p.Enforcer.GetImplicitRolesForUser(subject, companyID)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	e := Explanation{Subject: subject, Domain: companyID, Roles: roles}
+	if e.Roles == nil {
+		e.Roles = []string{}
+	}
+
+	for _, r := range p.Routes() {
+		if r.Method == method && util.KeyMatch2(path, r.Path) {
+			e.Route = r.Path
+			break
+		}
+	}
+
+	allowed, matched, err := p.Enforcer.EnforceEx(subject, companyID, path, method)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	e.Allowed = allowed
+	if allowed {
+		e.Matched = &Rule{Type: "p", Rule: matched}
+		e.Reason = fmt.Sprintf("allowed by %s", strings.Join(matched, ", "))
+		return e, nil
+	}
+
+	// Look for rules of the subject that match the path with another method,
+	// the most common cause of a denial.
+	subjects := append([]string{subject}, roles...)
+	var methods []string
+	policies, err := p.Enforcer.GetPolicy()
+	if err != nil {
+		return Explanation{}, err
+	}
+	for _, rule := range policies {
+		if slices.Contains(subjects, rule[0]) && (rule[1] == companyID || rule[1] == BuiltinDomain) &&
+			util.KeyMatch2(path, rule[2]) && !slices.Contains(methods, rule[3]) {
+			methods = append(methods, rule[3])
+		}
+	}
+	sort.Strings(methods)
+
+	switch {
+	case len(methods) > 0:
+		e.Reason = fmt.Sprintf("%s may call %s only with %s", subject, path, strings.Join(methods, ", "))
+	case e.Route == "":
+		e.Reason = fmt.Sprintf("no rule of %s matches %s %s, and no route serves it", subject, method, path)
+	default:
+		e.Reason = fmt.Sprintf("no rule of %s or its roles in domain %q matches %s %s", subject, companyID, method, path)
+	}
+
+	if subject != role && role != "" {
+		e.Reason += fmt.Sprintf("; the user has company roles, so the token role %q is not used", role)
+	}
+
+	return e, nil
+}
+
+// Resource returns the resource a route belongs to: the first segment of
+// its path.
+func Resource(path string) string {
+	resource, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return resource
+}
@@ -0,0 +1,135 @@
+package rbac
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrVersionNotFound is returned for unknown or pruned policy versions.
+var ErrVersionNotFound = errors.New("policy version not found")
+
+// Version describes one saved state of the runtime policy.
+type Version struct {
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Reason    string    `json:"reason"`
+	Rules     int       `json:"rules"`
+}
+
+// History keeps a snapshot of the runtime policy file for every change, so
+// a bad change can be rolled back. Snapshots live in dir as "<id>.csv"
+// next to an index.json describing them; only the newest keep versions are
+// retained.
+type History struct {
+	dir  string
+	keep int
+
+	mu       sync.Mutex
+	versions []Version
+}
+
+func NewHistory(dir string, keep int) (*History, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	h := &History{dir: dir, keep: keep}
+
+	data, err := os.ReadFile(h.indexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &h.versions); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Record stores content as a new version.
+func (h *History) Record(reason string, content []byte, rules int) (Version, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	v := Version{ID: 1, CreatedAt: time.Now().UTC(), Reason: reason, Rules: rules}
+	if n := len(h.versions); n > 0 {
+		v.ID = h.versions[n-1].ID + 1
+	}
+
+	if err := writeAtomic(h.snapshotPath(v.ID), content); err != nil {
+		return Version{}, err
+	}
+	h.versions = append(h.versions, v)
+
+	if h.keep > 0 && len(h.versions) > h.keep {
+		for _, old := range h.versions[:len(h.versions)-h.keep] {
+			if err := os.Remove(h.snapshotPath(old.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return Version{}, err
+			}
+		}
+		h.versions = append([]Version(nil), h.versions[len(h.versions)-h.keep:]...)
+	}
+
+	return v, h.saveIndex()
+}
+
+// List returns the retained versions, oldest first.
+func (h *History) List() []Version {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]Version(nil), h.versions...)
+}
+
+// Load returns the snapshot of a version.
+func (h *History) Load(id int) ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, v := range h.versions {
+		if v.ID == id {
+			return os.ReadFile(h.snapshotPath(id))
+		}
+	}
+
+	return nil, ErrVersionNotFound
+}
+
+// saveIndex must be called with the lock held.
+func (h *History) saveIndex() error {
+	data, err := json.MarshalIndent(h.versions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeAtomic(h.indexPath(), data)
+}
+
+func (h *History) indexPath() string {
+	return filepath.Join(h.dir, "index.json")
+}
+
+func (h *History) snapshotPath(id int) string {
+	return filepath.Join(h.dir, fmt.Sprintf("%d.csv", id))
+}
+
+func writeAtomic(path string, data []byte) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
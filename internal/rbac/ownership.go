@@ -0,0 +1,121 @@
+package rbac
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+)
+
+// createdAtLayouts are the timestamp formats backends use for created_at.
+// Those without an offset are in the backends' time zone.
+var createdAtLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+}
+
+// Caller holds the attributes of the caller in ownership rules (r.sub).
+type Caller struct {
+	Id        string
+	Role      string
+	CompanyId string
+}
+
+// Record holds the attributes of the record in ownership rules (r.obj).
+type Record struct {
+	Type      string
+	Id        string
+	Owner     string
+	CompanyId string
+	// AgeMinutes is the time since the record was created. It is +Inf when
+	// the creation time is unknown, so time-bound rules fail closed.
+	AgeMinutes float64
+}
+
+// Env holds settings ownership rules may refer to (r.env).
+type Env struct {
+	EditWindowMinutes float64
+}
+
+// Ownership evaluates the attribute-based rules of ownership_model.conf,
+// e.g. that workers may only change their own sales shortly after making
+// them.
+type Ownership struct {
+	enforcer   *casbin.SyncedEnforcer
+	env        Env
+	policyPath string
+	// location is the time zone of backend timestamps without an offset.
+	location *time.Location
+}
+
+// NewOwnership loads the ownership model and rules. editWindow is how long
+// after creation an owner of a record may still change it; location is the
+// time zone backends write creation times in when they give no offset.
+func NewOwnership(modelPath, policyPath string, editWindow time.Duration, location *time.Location) (*Ownership, error) {
+	enforcer, err := casbin.NewSyncedEnforcer(modelPath, policyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ownership{
+		enforcer:   enforcer,
+		env:        Env{EditWindowMinutes: editWindow.Minutes()},
+		policyPath: policyPath,
+		location:   location,
+	}, nil
+}
+
+// Allowed reports whether the caller may perform act on the record.
+func (o *Ownership) Allowed(caller Caller, record Record, act string) (bool, error) {
+	return o.enforcer.Enforce(caller, record, act, o.env)
+}
+
+// Reload rereads the ownership rules.
+func (o *Ownership) Reload() error {
+	return o.enforcer.LoadPolicy()
+}
+
+// Watch reloads the ownership rules whenever their file changes, until ctx
+// is done. A file that fails to load leaves the previous rules in place.
+func (o *Ownership) Watch(ctx context.Context, log *slog.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	seen := modTime(o.policyPath)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed := modTime(o.policyPath)
+		if changed.Equal(seen) {
+			continue
+		}
+		seen = changed
+
+		if err := o.Reload(); err != nil {
+			log.Error("Error reloading ownership rules, keeping the previous ones", "error", err.Error())
+			continue
+		}
+		log.Info("Ownership rules reloaded after file change")
+	}
+}
+
+// Age returns the minutes elapsed since createdAt, or +Inf when it cannot
+// be parsed. A timestamp without an offset is read in the backends' zone.
+func (o *Ownership) Age(createdAt string) float64 {
+	for _, layout := range createdAtLayouts {
+		if t, err := time.ParseInLocation(layout, createdAt, o.location); err == nil {
+			return time.Since(t).Minutes()
+		}
+	}
+	return math.Inf(1)
+}
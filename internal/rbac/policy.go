@@ -0,0 +1,274 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrInvalidRule  = errors.New("rule type must be p (sub, dom, obj, act) or g (user, role, dom)")
+	ErrRuleExists   = errors.New("rule already exists")
+	ErrRuleNotFound = errors.New("rule not found")
+	ErrBuiltinRule  = errors.New("built-in rules can only be changed in the policy file")
+)
+
+// ruleFields is the number of fields of each rule type in model.conf.
+var ruleFields = map[string]int{
+	"p": 4,
+	"g": 3,
+}
+
+// Rule is one policy ("p") or grouping ("g") rule.
+type Rule struct {
+	Type    string   `json:"type" binding:"required"`
+	Rule    []string `json:"rule" binding:"required"`
+	Builtin bool     `json:"builtin"`
+}
+
+// Policy owns the enforcer used by PermissionMiddleware and lets admins
+// change its rules at runtime.
+type Policy struct {
+	Enforcer *casbin.SyncedEnforcer
+
+	adapter     *Adapter
+	builtinPath string
+	storePath   string
+
+	mu     sync.RWMutex
+	routes gin.RoutesInfo
+}
+
+// NewPolicy builds the enforcer from the model, the built-in policy file and
+// the runtime rules in storePath. Every change of the runtime rules is kept
+// in versionsDir; keep limits how many versions are retained.
+func NewPolicy(modelPath, builtinPath, storePath, versionsDir string, keep int) (*Policy, error) {
+	history, err := NewHistory(versionsDir, keep)
+	if err != nil {
+		return nil, err
+	}
+
+	adapter := NewAdapter(builtinPath, storePath, history)
+	enforcer, err := casbin.NewSyncedEnforcer(modelPath, adapter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Policy{
+		Enforcer:    enforcer,
+		adapter:     adapter,
+		builtinPath: builtinPath,
+		storePath:   storePath,
+	}, nil
+}
+
+// SetRoutes sets the routes guarded by the policy. They are the source of
+// the permission catalog and of the consistency check.
+func (p *Policy) SetRoutes(routes gin.RoutesInfo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.routes = routes
+}
+
+// Routes returns the routes guarded by the policy.
+func (p *Policy) Routes() gin.RoutesInfo {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.routes
+}
+
+// Rules returns the rules whose subject and domain match the filters;
+// empty filters match everything.
+func (p *Policy) Rules(subject, domain string) ([]Rule, error) {
+	builtin, err := p.adapter.Builtin()
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := p.Enforcer.GetPolicy()
+	if err != nil {
+		return nil, err
+	}
+	links, err := p.Enforcer.GetGroupingPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(policies)+len(links))
+	for _, r := range policies {
+		if matches(r[0], subject) && matches(r[1], domain) {
+			rules = append(rules, Rule{Type: "p", Rule: r, Builtin: builtin["p, "+strings.Join(r, ", ")]})
+		}
+	}
+	for _, r := range links {
+		if matches(r[0], subject) && matches(r[2], domain) {
+			rules = append(rules, Rule{Type: "g", Rule: r, Builtin: builtin["g, "+strings.Join(r, ", ")]})
+		}
+	}
+
+	return rules, nil
+}
+
+// AddRule adds a runtime rule.
+func (p *Policy) AddRule(rule Rule) error {
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+
+	// The enforcer reports existing rules as added, so check first.
+	exists, err := p.has(rule)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrRuleExists
+	}
+
+	if rule.Type == "p" {
+		_, err = p.Enforcer.AddPolicy(rule.Rule)
+	} else {
+		_, err = p.Enforcer.AddGroupingPolicy(rule.Rule)
+	}
+	return err
+}
+
+// RemoveRule removes a runtime rule. Built-in rules cannot be removed.
+func (p *Policy) RemoveRule(rule Rule) error {
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+
+	builtin, err := p.adapter.Builtin()
+	if err != nil {
+		return err
+	}
+	if builtin[rule.Type+", "+strings.Join(rule.Rule, ", ")] {
+		return ErrBuiltinRule
+	}
+
+	exists, err := p.has(rule)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrRuleNotFound
+	}
+
+	if rule.Type == "p" {
+		_, err = p.Enforcer.RemovePolicy(rule.Rule)
+	} else {
+		_, err = p.Enforcer.RemoveGroupingPolicy(rule.Rule)
+	}
+	return err
+}
+
+// Reload rereads the policy from storage. On error the enforcer keeps the
+// policy it had.
+func (p *Policy) Reload() error {
+	return p.Enforcer.LoadPolicy()
+}
+
+// Versions returns the recorded versions of the runtime rules, oldest first.
+func (p *Policy) Versions() []Version {
+	return p.adapter.Versions()
+}
+
+// Rollback reloads the policy with the runtime rules of a version and then
+// stores them. If the rules cannot be stored, the policy is reloaded from
+// the store file again so the enforcer matches what is on disk.
+func (p *Policy) Rollback(id int) error {
+	reloaded := false
+	err := p.adapter.Rollback(id, func() error {
+		if err := p.Reload(); err != nil {
+			return err
+		}
+		reloaded = true
+		return nil
+	})
+	if err != nil && reloaded {
+		if reloadErr := p.Reload(); reloadErr != nil {
+			return errors.Join(err, reloadErr)
+		}
+	}
+	return err
+}
+
+// Watch reloads the policy whenever the built-in policy file or the runtime
+// rules file is changed by someone other than this gateway, e.g. an
+// operator editing policy.csv. It polls every interval and returns when
+// ctx is done.
+func (p *Policy) Watch(ctx context.Context, log *slog.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	builtinSeen := modTime(p.builtinPath)
+	storeSeen := modTime(p.storePath)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		builtin := modTime(p.builtinPath)
+		store := modTime(p.storePath)
+		if builtin.Equal(builtinSeen) && (store.Equal(storeSeen) || store.Equal(p.adapter.lastWrite())) {
+			storeSeen = store
+			continue
+		}
+		builtinSeen, storeSeen = builtin, store
+
+		if err := p.Reload(); err != nil {
+			log.Error("Error reloading policy, keeping the previous one", "error", err.Error())
+			continue
+		}
+		log.Info("Policy reloaded after file change")
+
+		if report, err := p.Check(); err == nil && !report.OK() {
+			log.Warn("Reloaded policy does not match the routes", "report", report.String())
+		}
+	}
+}
+
+func (p *Policy) has(rule Rule) (bool, error) {
+	if rule.Type == "p" {
+		return p.Enforcer.HasPolicy(rule.Rule)
+	}
+	return p.Enforcer.HasGroupingPolicy(rule.Rule)
+}
+
+func validateRule(rule Rule) error {
+	n, ok := ruleFields[rule.Type]
+	if !ok || len(rule.Rule) != n || slices.Contains(rule.Rule, "") {
+		return ErrInvalidRule
+	}
+	for _, v := range rule.Rule {
+		if strings.ContainsAny(v, ",\n") {
+			return ErrInvalidRule
+		}
+	}
+	return nil
+}
+
+func matches(value, filter string) bool {
+	return filter == "" || value == filter
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
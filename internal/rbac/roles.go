@@ -0,0 +1,447 @@
+package rbac
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/casbin/casbin/v2"
+)
+
+// BuiltinDomain is the domain of the rules shipped in policy.csv; they apply
+// to every company.
+const BuiltinDomain = "*"
+
+// rolePrefix namespaces company roles so they never collide with user IDs or
+// with the built-in roles in the grouping policy.
+const rolePrefix = "role:"
+
+var (
+	ErrInvalidRoleName   = errors.New("role name must be 1-32 lowercase letters, digits, '_' or '-'")
+	ErrReservedRole      = errors.New("role name is reserved")
+	ErrRoleExists        = errors.New("role already exists")
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrUnknownPermission = errors.New("permission is not in the catalog")
+	ErrInvalidParent     = errors.New("role can only inherit from worker or another role of the company")
+	ErrEmptyRole         = errors.New("role needs at least one permission or a parent role")
+	ErrReservedSubject   = errors.New("user id is reserved for a built-in or company role")
+)
+
+var roleNameRe = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// builtinRoles come from the token and cannot be created, changed or
+// assigned through the role API.
+var builtinRoles = map[string]bool{
+	"admin":  true,
+	"owner":  true,
+	"worker": true,
+}
+
+// delegableParents are the built-in roles a company role may inherit from.
+var delegableParents = map[string]bool{
+	"worker": true,
+}
+
+// grantor is the role whose permissions bound what a company role may be
+// granted: owners cannot hand out more than they have themselves.
+const grantor = "owner"
+
+// reservedPaths are owner-only routes that are never offered in the
+// permission catalog, so a company role cannot manage roles or
+// assignments.
+var reservedPaths = []string{
+	"/companies/admin",
+	"/companies/permissions",
+	"/companies/roles",
+	"/companies/users/:user_id/roles",
+	"/companies/users/:user_id/branches",
+}
+
+// Permission is one route of the gateway.
+type Permission struct {
+	Path   string `json:"path"`
+	Method string `json:"method"`
+}
+
+// Role is a company-defined role.
+type Role struct {
+	Name        string       `json:"name"`
+	Inherits    []string     `json:"inherits"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Manager maintains the company roles, their permissions and their
+// assignment to users on top of a domain-aware enforcer whose domain is the
+// company ID.
+type Manager struct {
+	policy   *Policy
+	enforcer *casbin.SyncedEnforcer
+
+	// mu serialises the read-modify-write sequences of the role API.
+	mu sync.Mutex
+}
+
+func NewManager(policy *Policy) *Manager {
+	return &Manager{policy: policy, enforcer: policy.Enforcer}
+}
+
+// Catalog returns the permissions an owner may grant to company roles:
+// every route the built-in owner role can reach, except the reserved ones.
+func (m *Manager) Catalog() ([]Permission, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	catalog, err := m.catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(catalog, func(i, j int) bool {
+		if catalog[i].Path != catalog[j].Path {
+			return catalog[i].Path < catalog[j].Path
+		}
+		return catalog[i].Method < catalog[j].Method
+	})
+
+	return catalog, nil
+}
+
+// Roles returns the roles defined by the company.
+func (m *Manager) Roles(companyID string) ([]Role, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names, err := m.roleNames(companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]Role, 0, len(names))
+	for _, name := range names {
+		role, err := m.role(companyID, name)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+// CreateRole defines a new company role.
+func (m *Manager) CreateRole(companyID string, role Role) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := validateName(role.Name); err != nil {
+		return err
+	}
+
+	exists, err := m.exists(companyID, role.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrRoleExists
+	}
+
+	policies, parents, err := m.rules(companyID, role)
+	if err != nil {
+		return err
+	}
+
+	return m.apply(policies, parents)
+}
+
+// UpdateRole replaces the parents and permissions of a company role.
+func (m *Manager) UpdateRole(companyID string, role Role) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	exists, err := m.exists(companyID, role.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrRoleNotFound
+	}
+
+	policies, parents, err := m.rules(companyID, role)
+	if err != nil {
+		return err
+	}
+
+	if err := m.clear(companyID, role.Name); err != nil {
+		return err
+	}
+
+	return m.apply(policies, parents)
+}
+
+// DeleteRole removes a company role together with its assignments.
+func (m *Manager) DeleteRole(companyID, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	exists, err := m.exists(companyID, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrRoleNotFound
+	}
+
+	if err := m.clear(companyID, name); err != nil {
+		return err
+	}
+
+	// Drop users and child roles assigned to it.
+	_, err = m.enforcer.RemoveFilteredGroupingPolicy(1, rolePrefix+name, companyID)
+	return err
+}
+
+// UserRoles returns the company roles assigned to the user.
+func (m *Manager) UserRoles(companyID, userID string) []string {
+	roles := m.enforcer.GetRolesForUserInDomain(userID, companyID)
+
+	names := make([]string, 0, len(roles))
+	for _, r := range roles {
+		if name, ok := strings.CutPrefix(r, rolePrefix); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// SetUserRoles replaces the company roles of the user. A user with company
+// roles is authorized by those roles only, instead of by the role in the
+// token; an empty list restores the token role. The user ID may not name a
+// built-in or company role, or the assignment would extend that role.
+func (m *Manager) SetUserRoles(companyID, userID string, names []string) error {
+	if builtinRoles[userID] || strings.HasPrefix(userID, rolePrefix) || userID == BuiltinDomain {
+		return ErrReservedSubject
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, name := range names {
+		exists, err := m.exists(companyID, name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrRoleNotFound
+		}
+	}
+
+	if _, err := m.enforcer.DeleteRolesForUserInDomain(userID, companyID); err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	rules := make([][]string, 0, len(names))
+	for _, name := range names {
+		rules = append(rules, []string{userID, rolePrefix + name, companyID})
+	}
+
+	_, err := m.enforcer.AddGroupingPolicies(rules)
+	return err
+}
+
+// Subject returns the policy subject a request is authorized as: the user
+// itself when it has company roles, the role from its token otherwise.
+func Subject(enforcer *casbin.SyncedEnforcer, userID, role, companyID string) string {
+	if userID != "" && companyID != "" && len(enforcer.GetRolesForUserInDomain(userID, companyID)) > 0 {
+		return userID
+	}
+	return role
+}
+
+// rules validates the role and returns its policy and grouping rules. It
+// must be called with the lock held.
+func (m *Manager) rules(companyID string, role Role) ([][]string, [][]string, error) {
+	if len(role.Permissions) == 0 && len(role.Inherits) == 0 {
+		return nil, nil, ErrEmptyRole
+	}
+
+	catalog, err := m.catalog()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sub := rolePrefix + role.Name
+
+	var policies [][]string
+	for _, p := range role.Permissions {
+		p.Method = strings.ToUpper(p.Method)
+		if !slices.Contains(catalog, p) {
+			return nil, nil, ErrUnknownPermission
+		}
+		policies = append(policies, []string{sub, companyID, p.Path, p.Method})
+	}
+
+	var parents [][]string
+	for _, parent := range role.Inherits {
+		if delegableParents[parent] {
+			parents = append(parents, []string{sub, parent, companyID})
+			continue
+		}
+
+		exists, err := m.exists(companyID, parent)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !exists || m.inherits(companyID, rolePrefix+parent, sub) {
+			return nil, nil, ErrInvalidParent
+		}
+		parents = append(parents, []string{sub, rolePrefix + parent, companyID})
+	}
+
+	return policies, parents, nil
+}
+
+func (m *Manager) apply(policies, parents [][]string) error {
+	if len(policies) > 0 {
+		if _, err := m.enforcer.AddPolicies(policies); err != nil {
+			return err
+		}
+	}
+	if len(parents) > 0 {
+		if _, err := m.enforcer.AddGroupingPolicies(parents); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// inherits reports whether sub is, or transitively inherits from, ancestor.
+// It keeps role hierarchies free of cycles.
+func (m *Manager) inherits(companyID, sub, ancestor string) bool {
+	if sub == ancestor {
+		return true
+	}
+
+	roles, err := m.enforcer.GetImplicitRolesForUser(sub, companyID)
+	if err != nil {
+		return true
+	}
+	return slices.Contains(roles, ancestor)
+}
+
+// clear removes the permissions and parents of the role. It must be called
+// with the lock held.
+func (m *Manager) clear(companyID, name string) error {
+	sub := rolePrefix + name
+
+	if _, err := m.enforcer.RemoveFilteredPolicy(0, sub, companyID); err != nil {
+		return err
+	}
+	if _, err := m.enforcer.DeleteRolesForUserInDomain(sub, companyID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Manager) role(companyID, name string) (Role, error) {
+	sub := rolePrefix + name
+	role := Role{Name: name, Inherits: []string{}, Permissions: []Permission{}}
+
+	policies, err := m.enforcer.GetFilteredPolicy(0, sub, companyID)
+	if err != nil {
+		return Role{}, err
+	}
+	for _, p := range policies {
+		role.Permissions = append(role.Permissions, Permission{Path: p[2], Method: p[3]})
+	}
+
+	for _, parent := range m.enforcer.GetRolesForUserInDomain(sub, companyID) {
+		role.Inherits = append(role.Inherits, strings.TrimPrefix(parent, rolePrefix))
+	}
+
+	return role, nil
+}
+
+func (m *Manager) roleNames(companyID string) ([]string, error) {
+	var names []string
+
+	policies, err := m.enforcer.GetFilteredPolicy(1, companyID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range policies {
+		if name, ok := strings.CutPrefix(p[0], rolePrefix); ok && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	links, err := m.enforcer.GetFilteredGroupingPolicy(2, companyID)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range links {
+		if name, ok := strings.CutPrefix(g[0], rolePrefix); ok && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *Manager) exists(companyID, name string) (bool, error) {
+	names, err := m.roleNames(companyID)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(names, name), nil
+}
+
+// catalog returns the grantable permissions. It must be called with the
+// lock held.
+func (m *Manager) catalog() ([]Permission, error) {
+	var catalog []Permission
+	for _, r := range m.policy.Routes() {
+		if isReserved(r.Path) {
+			continue
+		}
+
+		allowed, err := m.enforcer.Enforce(grantor, BuiltinDomain, r.Path, r.Method)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			catalog = append(catalog, Permission{Path: r.Path, Method: r.Method})
+		}
+	}
+	return catalog, nil
+}
+
+func validateName(name string) error {
+	if !roleNameRe.MatchString(name) {
+		return ErrInvalidRoleName
+	}
+	if builtinRoles[name] {
+		return ErrReservedRole
+	}
+	return nil
+}
+
+func isReserved(path string) bool {
+	for _, prefix := range reservedPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+// Package tracing sets up OpenTelemetry tracing: the exporter chosen by
+// TRACING_EXPORTER, W3C trace context propagation, and spans for backend
+// RPCs.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"gateway/config"
+	"os"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const tracerName = "gateway"
+
+// Provider owns the exporter of the process. With TRACING_EXPORTER=none it
+// exports nothing, but trace context is still passed on to the backends.
+type Provider struct {
+	tp   *sdktrace.TracerProvider
+	file *os.File
+}
+
+// New installs the global tracer provider and propagator.
+func New(ctx context.Context, cfg *config.Config) (*Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	p := &Provider{}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.TRACING_EXPORTER {
+	case "none":
+		return p, nil
+	case "otlp":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.TRACING_OTLP_ENDPOINT)}
+		if cfg.TRACING_OTLP_INSECURE {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "file":
+		p.file, err = os.OpenFile(cfg.TRACING_FILE, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(p.file))
+	}
+	if err != nil {
+		return nil, errors.Join(err, p.closeFile())
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(cfg.TRACING_SERVICE_NAME)))
+	if err != nil {
+		return nil, errors.Join(err, exporter.Shutdown(ctx), p.closeFile())
+	}
+
+	p.tp = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(float64(cfg.TRACING_SAMPLE_PERCENT)/100))),
+	)
+	otel.SetTracerProvider(p.tp)
+
+	return p, nil
+}
+
+// Close flushes the spans still buffered and stops the exporter.
+func (p *Provider) Close(ctx context.Context) error {
+	if p.tp == nil {
+		return nil
+	}
+	return errors.Join(p.tp.Shutdown(ctx), p.closeFile())
+}
+
+func (p *Provider) closeFile() error {
+	if p.file == nil {
+		return nil
+	}
+	return p.file.Close()
+}
+
+// Tracer returns the tracer of the gateway.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Start starts a span as a child of the span in ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// End records err on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// UnaryClientInterceptor wraps every backend RPC, retries included, in a
+// client span and sends its trace context as gRPC metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		service, name := path.Split(method)
+		service = strings.Trim(service, "/")
+
+		ctx, span := Start(ctx, strings.TrimPrefix(method, "/"),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.RPCSystemGRPC,
+				semconv.RPCService(service),
+				semconv.RPCMethod(name),
+			),
+		)
+
+		md, _ := metadata.FromOutgoingContext(ctx)
+		md = md.Copy()
+		otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+		ctx = metadata.NewOutgoingContext(ctx, md)
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(status.Code(err))))
+		End(span, err)
+
+		return err
+	}
+}
+
+// metadataCarrier adapts gRPC metadata to propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (m metadataCarrier) Get(key string) string {
+	if values := metadata.MD(m).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (m metadataCarrier) Set(key, value string) {
+	metadata.MD(m).Set(key, value)
+}
+
+func (m metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
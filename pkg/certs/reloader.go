@@ -0,0 +1,155 @@
+// Package certs builds TLS client configurations whose CA bundle and
+// client certificate are reread from disk when the files change, so
+// certificates can be rotated without restarting the gateway.
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader holds the current CA pool and client certificate.
+type Reloader struct {
+	caPath, certPath, keyPath string
+	log                       *slog.Logger
+
+	mu      sync.Mutex
+	roots   *x509.CertPool
+	cert    *tls.Certificate
+	modTime map[string]time.Time
+}
+
+// NewReloader loads the files. caPath may be empty to trust the system
+// roots; certPath and keyPath may be empty when no client certificate is
+// sent.
+func NewReloader(caPath, certPath, keyPath string, log *slog.Logger) (*Reloader, error) {
+	if (certPath == "") != (keyPath == "") {
+		return nil, errors.New("a client certificate needs both a certificate and a key file")
+	}
+
+	r := &Reloader{caPath: caPath, certPath: certPath, keyPath: keyPath, log: log}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// TLSConfig returns a client configuration backed by the reloader. The
+// server certificate is verified against the current CA pool on every
+// handshake; serverName overrides the name taken from the target address.
+func (r *Reloader) TLSConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		// The chain is verified in VerifyConnection instead, against the CA
+		// pool current at the time of the handshake.
+		InsecureSkipVerify: true,
+		VerifyConnection:   r.verify,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			_, cert := r.current()
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		},
+	}
+}
+
+func (r *Reloader) verify(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server sent no certificate")
+	}
+
+	roots, _ := r.current()
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       cs.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// current rereads the files if any of them changed and returns the CA pool
+// and client certificate. A failed reload keeps the previous ones.
+func (r *Reloader) current() (*x509.CertPool, *tls.Certificate) {
+	r.mu.Lock()
+	changed := r.changed()
+	r.mu.Unlock()
+
+	if changed {
+		if err := r.load(); err != nil {
+			r.log.Error("Error reloading TLS certificates, keeping the previous ones", "error", err.Error())
+		} else {
+			r.log.Info("Reloaded TLS certificates", "ca", r.caPath, "cert", r.certPath)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.roots, r.cert
+}
+
+// changed reports whether a file has a different modification time than
+// at the last load. It must be called with mu held.
+func (r *Reloader) changed() bool {
+	for path, loaded := range r.modTime {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Equal(loaded) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reloader) load() error {
+	modTime := make(map[string]time.Time)
+	for _, path := range []string{r.caPath, r.certPath, r.keyPath} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		modTime[path] = info.ModTime()
+	}
+
+	var roots *x509.CertPool
+	if r.caPath != "" {
+		pem, err := os.ReadFile(r.caPath)
+		if err != nil {
+			return err
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", r.caPath)
+		}
+	}
+
+	var cert *tls.Certificate
+	if r.certPath != "" {
+		pair, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+		if err != nil {
+			return err
+		}
+		cert = &pair
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roots, r.cert, r.modTime = roots, cert, modTime
+
+	return nil
+}
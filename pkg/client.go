@@ -1,45 +1,164 @@
 package pkg
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"gateway/config"
+	"gateway/pkg/certs"
+	"gateway/pkg/pool"
+	"gateway/pkg/resilience"
+	"log/slog"
+	"sync"
+	"time"
 
 	pbc "gateway/internal/generated/company"
 	pbd "gateway/internal/generated/debts"
 	pbp "gateway/internal/generated/products"
 	pbu "gateway/internal/generated/user"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
-	"log"
 )
 
-func NewUserClient(cfg *config.Config) pbu.AuthServiceClient {
-	conn, err := grpc.NewClient("crm-admin_auth"+cfg.USER_SERVICE, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.Fatalf("failed to connect to User Service: %v", err)
+// Conns are the routes to the backend services. They are owned by
+// main, which closes them on shutdown.
+type Conns struct {
+	// User serves both the auth and the company API.
+	User    *pool.Route
+	Product *pool.Route
+	Debt    *pool.Route
+}
+
+// resolveTimeout bounds the first resolution of the endpoints in Dial.
+const resolveTimeout = 5 * time.Second
+
+// Dial creates the routes of cfg. Connecting happens lazily on the first
+// call. Every call runs interceptors first and then applies the deadline,
+// retry and circuit breaker settings of its backend.
+func Dial(cfg *config.Config, log *slog.Logger, interceptors ...grpc.UnaryClientInterceptor) (*Conns, error) {
+	conns := &Conns{}
+
+	var err error
+	if conns.User, err = dial("user", cfg.USER_SERVICE_CLIENT, log, interceptors); err != nil {
+		return nil, fmt.Errorf("failed to connect to User Service: %w", err)
 	}
-	return pbu.NewAuthServiceClient(conn)
+	if conns.Product, err = dial("product", cfg.PRODUCT_SERVICE_CLIENT, log, interceptors); err != nil {
+		conns.Close(nil)
+		return nil, fmt.Errorf("failed to connect to Product Service: %w", err)
+	}
+	if conns.Debt, err = dial("debt", cfg.DEBT_SERVICE_CLIENT, log, interceptors); err != nil {
+		conns.Close(nil)
+		return nil, fmt.Errorf("failed to connect to Debt Service: %w", err)
+	}
+
+	return conns, nil
 }
 
-func NewProductClient(cfg *config.Config) pbp.ProductsClient {
-	conn, err := grpc.NewClient("product"+cfg.PRODUCT_SERVICE, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// dial builds the route of backend name: its primary pool and, when
+// configured, its canary and shadow pools. They share the TLS settings,
+// and each has its own circuit breaker.
+func dial(name string, opts config.Backend, log *slog.Logger, interceptors []grpc.UnaryClientInterceptor) (*pool.Route, error) {
+	creds, err := transportCredentials(opts, log)
 	if err != nil {
-		log.Fatalf("failed to connect to Product Service: %v", err)
+		return nil, err
+	}
+	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	newPool := func(name string, addresses []string, file string) *pool.Pool {
+		opts := opts
+		opts.Addresses, opts.EndpointsFile = addresses, file
+		return pool.New(ctx, name, opts, dialOpts, []grpc.UnaryClientInterceptor{resilience.UnaryClientInterceptor(name, opts, log)}, log)
+	}
+
+	primary := newPool(name, opts.Addresses, opts.EndpointsFile)
+
+	var canary, shadow *pool.Pool
+	if len(opts.CanaryAddresses) > 0 {
+		canary = newPool(name+"-canary", opts.CanaryAddresses, "")
 	}
-	return pbp.NewProductsClient(conn)
+	if len(opts.ShadowAddresses) > 0 {
+		shadow = newPool(name+"-shadow", opts.ShadowAddresses, "")
+	}
+
+	return pool.NewRoute(name, opts, primary, canary, shadow, interceptors, log), nil
 }
 
-func NewCompanyClient(cfg *config.Config) pbc.CompanyServiceClient {
-	conn, err := grpc.NewClient("crm-admin_auth"+cfg.USER_SERVICE, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.Fatalf("failed to connect to Client Service: %v", err)
+// Watch keeps the endpoints of every pool up to date until ctx is done.
+func (c *Conns) Watch(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, p := range []*pool.Route{c.User, c.Product, c.Debt} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Watch(ctx)
+		}()
 	}
-	return pbc.NewCompanyServiceClient(conn)
+	wg.Wait()
 }
 
-func NewDebtClient(cfg *config.Config) pbd.DebtsServiceClient {
-	conn, err := grpc.NewClient("debts-service"+cfg.DEBT_SERVICE, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// transportCredentials returns plaintext credentials in insecure mode and
+// TLS credentials whose certificates follow changes on disk otherwise.
+func transportCredentials(opts config.Backend, log *slog.Logger) (credentials.TransportCredentials, error) {
+	if opts.TLS == "insecure" {
+		return insecure.NewCredentials(), nil
+	}
+
+	cert, key := "", ""
+	if opts.TLS == "mtls" {
+		cert, key = opts.TLSCert, opts.TLSKey
+	}
+
+	reloader, err := certs.NewReloader(opts.TLSCA, cert, key, log)
 	if err != nil {
-		log.Fatalf("failed to connect to Debt Service: %v", err)
+		return nil, err
 	}
-	return pbd.NewDebtsServiceClient(conn)
+
+	return credentials.NewTLS(reloader.TLSConfig(opts.TLSServerName)), nil
+}
+
+// Close closes every open connection, logging each one when log is set.
+func (c *Conns) Close(log *slog.Logger) error {
+	var errs []error
+	for _, conn := range []struct {
+		name string
+		conn *pool.Route
+	}{{"user", c.User}, {"product", c.Product}, {"debt", c.Debt}} {
+		if conn.conn == nil {
+			continue
+		}
+
+		err := conn.conn.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", conn.name, err))
+		}
+		if log != nil {
+			if err != nil {
+				log.Error("Error closing gRPC connection", "service", conn.name, "error", err.Error())
+			} else {
+				log.Info("Closed gRPC connection", "service", conn.name, "target", conn.conn.Target())
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func NewUserClient(conns *Conns) pbu.AuthServiceClient {
+	return pbu.NewAuthServiceClient(conns.User)
+}
+
+func NewProductClient(conns *Conns) pbp.ProductsClient {
+	return pbp.NewProductsClient(conns.Product)
+}
+
+func NewCompanyClient(conns *Conns) pbc.CompanyServiceClient {
+	return pbc.NewCompanyServiceClient(conns.User)
+}
+
+func NewDebtClient(conns *Conns) pbd.DebtsServiceClient {
+	return pbd.NewDebtsServiceClient(conns.Debt)
 }
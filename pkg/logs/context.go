@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ContextHandler добавляет к каждой записи атрибуты из её контекста,
+// например request_id текущего запроса
+type ContextHandler struct {
+	slog.Handler
+	attrs func(ctx context.Context) []slog.Attr
+}
+
+// Создание обёртки над handler
+func NewContextHandler(handler slog.Handler, attrs func(ctx context.Context) []slog.Attr) *ContextHandler {
+	return &ContextHandler{Handler: handler, attrs: attrs}
+}
+
+// Handle добавляет атрибуты контекста и передаёт запись дальше
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		r.AddAttrs(h.attrs(ctx)...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs добавляет атрибуты
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs), attrs: h.attrs}
+}
+
+// WithGroup добавляет поддержку группировки
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name), attrs: h.attrs}
+}
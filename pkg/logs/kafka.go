@@ -0,0 +1,194 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"gateway/internal/metrics"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// maxReconnectWait ограничивает паузу между попытками подключения к Kafka
+const maxReconnectWait = 30 * time.Second
+
+// KafkaWriter асинхронно отправляет записи в Kafka, по одной на Write. Пока Kafka недоступна, а
+// также когда буфер продюсера полон или отправка не удалась, записи
+// дописываются в резервный файл, поэтому память ограничена размером буфера
+type KafkaWriter struct {
+	brokers []string
+	topic   string
+	config  *sarama.Config
+	log     *slog.Logger
+
+	mu       sync.RWMutex
+	client   sarama.Client
+	producer sarama.AsyncProducer
+	closed   bool
+
+	fallbackMu sync.Mutex
+	fallback   *os.File
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Создание writer. Подключение к Kafka идёт в фоне и не задерживает запуск,
+// его ход пишется в log
+func NewKafkaWriter(brokers []string, topic string, buffer int, fallback string, log *slog.Logger) (*KafkaWriter, error) {
+	file, err := os.OpenFile(fallback, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	config := sarama.NewConfig()
+	config.ChannelBufferSize = buffer
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+	config.Producer.Retry.MaxBufferLength = buffer
+	config.Producer.Return.Errors = true
+	config.Net.DialTimeout = 5 * time.Second
+	config.Metadata.Retry.Max = 1
+
+	s := &KafkaWriter{
+		brokers:  brokers,
+		topic:    topic,
+		config:   config,
+		log:      log,
+		fallback: file,
+		stop:     make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.connect()
+
+	return s, nil
+}
+
+// connect подключается к Kafka с растущей паузой между попытками, а после
+// подключения пишет в резервный файл записи, которые не удалось отправить
+func (s *KafkaWriter) connect() {
+	defer s.wg.Done()
+
+	wait := time.Second
+	for {
+		client, err := sarama.NewClient(s.brokers, s.config)
+		if err == nil {
+			var producer sarama.AsyncProducer
+			if producer, err = sarama.NewAsyncProducerFromClient(client); err == nil {
+				s.mu.Lock()
+				if s.closed {
+					s.mu.Unlock()
+					producer.Close()
+					client.Close()
+					return
+				}
+				s.client, s.producer = client, producer
+				s.mu.Unlock()
+
+				s.log.Info("Connected to Kafka", "topic", s.topic)
+
+				// Канал закрывается, когда продюсер закрыт и всё отправил
+				for perr := range producer.Errors() {
+					if value, err := perr.Msg.Value.Encode(); err == nil {
+						s.fail(value)
+					}
+				}
+				return
+			}
+			client.Close()
+		}
+
+		s.log.Warn("Error connecting to Kafka, retrying", "topic", s.topic, "retry_in", wait.String(), "error", err.Error())
+
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(wait):
+		}
+		wait = min(wait*2, maxReconnectWait)
+	}
+}
+
+// Write отправляет одну запись без ожидания. Slog переиспользует буфер,
+// поэтому запись копируется
+func (s *KafkaWriter) Write(p []byte) (int, error) {
+	msg := bytes.Clone(bytes.TrimSuffix(p, []byte("\n")))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.producer != nil && !s.closed {
+		select {
+		case s.producer.Input() <- &sarama.ProducerMessage{Topic: s.topic, Value: sarama.ByteEncoder(msg)}:
+			return len(p), nil
+		default:
+		}
+	}
+
+	return len(p), s.fail(msg)
+}
+
+// fail дописывает неотправленную запись в резервный файл
+func (s *KafkaWriter) fail(msg []byte) error {
+	metrics.KafkaSendFailures.WithLabelValues(s.topic).Inc()
+
+	s.fallbackMu.Lock()
+	defer s.fallbackMu.Unlock()
+
+	_, err := s.fallback.Write(append(msg, '\n'))
+	return err
+}
+
+// Ping проверяет, что брокеры отвечают, обновляя метаданные топика
+func (s *KafkaWriter) Ping(ctx context.Context) error {
+	s.mu.RLock()
+	client := s.client
+	s.mu.RUnlock()
+
+	if client == nil {
+		return errors.New("not connected to Kafka")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- client.RefreshMetadata(s.topic)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close отправляет записи из буфера и закрывает продюсер и клиента.
+// Записи, пришедшие после Close, идут в резервный файл
+func (s *KafkaWriter) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	client, producer := s.client, s.producer
+	s.mu.Unlock()
+
+	close(s.stop)
+	if producer != nil {
+		producer.AsyncClose()
+	}
+	s.wg.Wait()
+
+	var errs []error
+	if client != nil && !client.Closed() {
+		errs = append(errs, client.Close())
+	}
+
+	s.fallbackMu.Lock()
+	defer s.fallbackMu.Unlock()
+	errs = append(errs, s.fallback.Sync())
+
+	return errors.Join(errs...)
+}
@@ -2,102 +2,159 @@ package logger
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"gateway/config"
+	"io"
 	"log/slog"
 	"os"
-
-	"github.com/IBM/sarama"
+	"time"
 )
 
-// KafkaLogHandler с реализацией slog.Handler
+// KafkaLogHandler с реализацией slog.Handler: пишет записи в файл и
+// отправляет в Kafka в формате JSON, у каждого свой минимальный уровень
 type KafkaLogHandler struct {
-	fileHandler slog.Handler
-	producer    sarama.SyncProducer
-	topic       string
+	fileHandler  slog.Handler
+	kafkaHandler slog.Handler
+	sink         *KafkaWriter
+	// file файл fileHandler, если его открыл NewLogger
+	file io.Closer
 }
 
-// Создание нового Kafka-логгера
-func NewKafkaLogHandler(fileHandler slog.Handler, brokers, topic string) *KafkaLogHandler {
-	config := sarama.NewConfig()
-	config.Producer.RequiredAcks = sarama.WaitForAll
-	config.Producer.Retry.Max = 5
-	config.Producer.Return.Successes = true
+// KafkaOptions настройки отправки логов в Kafka
+type KafkaOptions struct {
+	Brokers []string
+	Topic   string
+	// Level минимальный уровень записей, отправляемых в Kafka
+	Level slog.Leveler
+	// Buffer сколько записей может ждать отправки, остальные идут в Fallback
+	Buffer int
+	// Fallback файл для записей, которые не удалось отправить
+	Fallback string
+}
 
-	producer, err := sarama.NewSyncProducer([]string{brokers}, config)
+// Создание нового Kafka-логгера. Недоступность Kafka не ошибка: записи
+// идут в резервный файл, пока подключение не установится. Сообщения о
+// подключении пишутся только в fileHandler, чтобы не уходить в саму Kafka
+func NewKafkaLogHandler(fileHandler slog.Handler, opts KafkaOptions) (*KafkaLogHandler, error) {
+	sink, err := NewKafkaWriter(opts.Brokers, opts.Topic, opts.Buffer, opts.Fallback, slog.New(fileHandler))
 	if err != nil {
-		log.Fatal("Ошибка подключения к Kafka:", err)
+		return nil, err
 	}
 
 	return &KafkaLogHandler{
-		fileHandler: fileHandler,
-		producer:    producer,
-		topic:       topic,
-	}
+		fileHandler:  fileHandler,
+		kafkaHandler: slog.NewJSONHandler(sink, &slog.HandlerOptions{Level: opts.Level}),
+		sink:         sink,
+	}, nil
 }
 
-// Handle отправляет логи в Kafka + файл
+// Handle пишет запись в файл и в Kafka, если её уровень там включён
 func (h *KafkaLogHandler) Handle(ctx context.Context, r slog.Record) error {
-	h.fileHandler.Handle(ctx, r)
-
-	msg := fmt.Sprintf("[%s] %s", r.Level, r.Message)
-
-	if r.Level == -4 {
-
-		_, _, err := h.producer.SendMessage(&sarama.ProducerMessage{
-			Topic: h.topic,
-			Value: sarama.StringEncoder(msg),
-		})
-		if err != nil {
-			log.Println("Ошибка отправки лога в Kafka:", err)
-		}
+	var errs []error
+	if h.fileHandler.Enabled(ctx, r.Level) {
+		errs = append(errs, h.fileHandler.Handle(ctx, r))
 	}
-
-	return nil
+	if h.kafkaHandler.Enabled(ctx, r.Level) {
+		errs = append(errs, h.kafkaHandler.Handle(ctx, r))
+	}
+	return errors.Join(errs...)
 }
 
-// Enabled проверяет, активен ли этот Handler
+// Enabled проверяет, нужна ли запись этого уровня файлу или Kafka
 func (h *KafkaLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return true
+	return h.fileHandler.Enabled(ctx, level) || h.kafkaHandler.Enabled(ctx, level)
 }
 
 // WithAttrs добавляет атрибуты
 func (h *KafkaLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &KafkaLogHandler{
-		fileHandler: h.fileHandler.WithAttrs(attrs),
-		producer:    h.producer,
-		topic:       h.topic,
+		fileHandler:  h.fileHandler.WithAttrs(attrs),
+		kafkaHandler: h.kafkaHandler.WithAttrs(attrs),
+		sink:         h.sink,
 	}
 }
 
 // WithGroup добавляет поддержку группировки
 func (h *KafkaLogHandler) WithGroup(name string) slog.Handler {
 	return &KafkaLogHandler{
-		fileHandler: h.fileHandler.WithGroup(name),
-		producer:    h.producer,
-		topic:       h.topic,
+		fileHandler:  h.fileHandler.WithGroup(name),
+		kafkaHandler: h.kafkaHandler.WithGroup(name),
+		sink:         h.sink,
 	}
 }
 
-// Закрываем Kafka Producer
-func (h *KafkaLogHandler) Close() {
-	h.producer.Close()
+// Ping проверяет, что брокеры отвечают
+func (h *KafkaLogHandler) Ping(ctx context.Context) error {
+	return h.sink.Ping(ctx)
 }
 
-// Создание нового логгера
-func NewLogger() *slog.Logger {
-	opts := slog.HandlerOptions{Level: slog.LevelDebug}
+// Закрываем Kafka Producer и клиента, дожидаясь отправки буфера, и файл лога
+func (h *KafkaLogHandler) Close() error {
+	err := h.sink.Close()
+	if h.file != nil {
+		err = errors.Join(err, h.file.Close())
+	}
+	return err
+}
 
-	file, err := os.OpenFile("app.log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+// Создание нового логгера. Handler нужно закрыть при остановке
+func NewLogger(cfg *config.Config) (*slog.Logger, *KafkaLogHandler, error) {
+	var level, kafkaLevel slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LOG_LEVEL)); err != nil {
+		return nil, nil, err
+	}
+	if err := kafkaLevel.UnmarshalText([]byte(cfg.KAFKA_LOG_LEVEL)); err != nil {
+		return nil, nil, err
+	}
+
+	if err := os.MkdirAll(cfg.LOG_DIR, 0755); err != nil {
+		return nil, nil, err
+	}
+
+	file, err := NewRotatingFile(cfg.LOG_FILE, RotateOptions{
+		MaxSize:  int64(cfg.LOG_MAX_SIZE) << 20,
+		MaxAge:   time.Hour * time.Duration(cfg.LOG_MAX_AGE),
+		Backups:  cfg.LOG_MAX_BACKUPS,
+		Compress: cfg.LOG_COMPRESS,
+	})
 	if err != nil {
-		log.Fatal("Ошибка открытия файла лога:", err)
+		return nil, nil, err
 	}
 
-	fileHandler := slog.NewTextHandler(file, &opts)
+	fileHandler := slog.NewTextHandler(file, &slog.HandlerOptions{Level: level})
 
-	kafkaHandler := NewKafkaLogHandler(fileHandler, "38.242.212.205:9092", "logs")
+	kafkaHandler, err := NewKafkaLogHandler(fileHandler, KafkaOptions{
+		Brokers:  cfg.KAFKA_BROKERS,
+		Topic:    cfg.KAFKA_LOG_TOPIC,
+		Level:    kafkaLevel,
+		Buffer:   cfg.KAFKA_LOG_BUFFER,
+		Fallback: cfg.KAFKA_LOG_FALLBACK_FILE,
+	})
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	kafkaHandler.file = file
 
-	log.Println("Connected to Kafka with Sarama")
+	// Маскирование стоит перед файлом и Kafka, чтобы данные не попали ни туда, ни туда
+	redactor, err := NewRedactorFromConfig(cfg)
+	if err != nil {
+		kafkaHandler.Close()
+		return nil, nil, err
+	}
+
+	return slog.New(NewRedactHandler(kafkaHandler, redactor)), kafkaHandler, nil
+}
 
-	return slog.New(kafkaHandler)
+// Создание Redactor по LOG_REDACT_KEYS и LOG_REDACT_PATTERNS
+func NewRedactorFromConfig(cfg *config.Config) (*Redactor, error) {
+	redactor, err := NewRedactor(RedactOptions{
+		Keys:     cfg.LOG_REDACT_KEYS,
+		Patterns: cfg.LOG_REDACT_PATTERNS,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("LOG_REDACT_PATTERNS: %w", err)
+	}
+	return redactor, nil
 }
@@ -0,0 +1,221 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// redacted заменяет значения полей с чувствительными именами
+const redacted = "[REDACTED]"
+
+// redactPattern находит чувствительные данные в тексте и маскирует их
+type redactPattern struct {
+	re   *regexp.Regexp
+	mask func(match string) string
+}
+
+// secretPair находит пары вида password=..., "secret": "..." в тексте
+// ошибок и запросов
+var secretPair = regexp.MustCompile(`(?i)\b(password|passwd|secret|token)("?\s*[:=]\s*"?)[^\s"',&]+`)
+
+// redactPatterns встроенные шаблоны, выбираемые по имени
+var redactPatterns = map[string][]redactPattern{
+	// Телефоны: +998 90 123-45-67, 998901234567, 90 123 45 67
+	"phone": {
+		{re: regexp.MustCompile(`\+\d[\d\s()-]{8,16}\d`), mask: maskDigits},
+		{re: regexp.MustCompile(`\b998\d{9}\b`), mask: maskDigits},
+		{re: regexp.MustCompile(`\b\d{2}[\s-]\d{3}[\s-]\d{2}[\s-]\d{2}\b`), mask: maskDigits},
+	},
+	// Почта: остаётся первая буква и домен
+	"email": {
+		{re: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`), mask: maskEmail},
+	},
+	// JWT и заголовок Authorization
+	"token": {
+		{re: regexp.MustCompile(`\beyJ[\w-]*\.[\w-]*\.[\w-]*`), mask: func(string) string { return redacted }},
+		{re: regexp.MustCompile(`(?i)\b(bearer|basic)\s+[\w.~+/=-]+`), mask: func(match string) string {
+			scheme, _, _ := strings.Cut(match, " ")
+			return scheme + " " + redacted
+		}},
+	},
+	// Имя и разделитель пары остаются, значение скрывается
+	"secret": {
+		{re: secretPair, mask: func(match string) string { return secretPair.ReplaceAllString(match, "${1}${2}"+redacted) }},
+	},
+}
+
+// RedactOptions правила маскирования
+type RedactOptions struct {
+	// Keys имена полей, значения которых скрываются целиком. Имя поля
+	// совпадает, если содержит одно из них без учёта регистра
+	Keys []string
+	// Patterns имена встроенных шаблонов (phone, email, token, secret),
+	// применяемых к сообщению и ко всем строковым значениям
+	Patterns []string
+}
+
+// Redactor применяет правила маскирования к атрибутам, строкам и JSON
+type Redactor struct {
+	keys     []string
+	patterns []redactPattern
+}
+
+// Создание Redactor по правилам
+func NewRedactor(opts RedactOptions) (*Redactor, error) {
+	r := &Redactor{}
+	for _, key := range opts.Keys {
+		r.keys = append(r.keys, strings.ToLower(key))
+	}
+	for _, name := range opts.Patterns {
+		patterns, ok := redactPatterns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown redaction pattern %q", name)
+		}
+		r.patterns = append(r.patterns, patterns...)
+	}
+	return r, nil
+}
+
+// RedactHandler маскирует персональные данные и секреты до того, как
+// запись попадёт в файл или Kafka
+type RedactHandler struct {
+	next     slog.Handler
+	redactor *Redactor
+}
+
+// Создание обёртки над handler
+func NewRedactHandler(next slog.Handler, redactor *Redactor) *RedactHandler {
+	return &RedactHandler{next: next, redactor: redactor}
+}
+
+// Enabled передаёт проверку дальше
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle маскирует сообщение и атрибуты и передаёт запись дальше
+func (h *RedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	clean := slog.NewRecord(r.Time, r.Level, h.redactor.String(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		clean.AddAttrs(h.redactor.Attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, clean)
+}
+
+// WithAttrs маскирует атрибуты
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		clean[i] = h.redactor.Attr(a)
+	}
+	return &RedactHandler{next: h.next.WithAttrs(clean), redactor: h.redactor}
+}
+
+// WithGroup добавляет поддержку группировки
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{next: h.next.WithGroup(name), redactor: h.redactor}
+}
+
+// Attr маскирует атрибут: целиком по имени или по шаблонам в значении
+func (r *Redactor) Attr(a slog.Attr) slog.Attr {
+	if r.Sensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		group := value.Group()
+		clean := make([]any, len(group))
+		for i, ga := range group {
+			clean[i] = r.Attr(ga)
+		}
+		return slog.Group(a.Key, clean...)
+	case slog.KindString:
+		return slog.String(a.Key, r.String(value.String()))
+	case slog.KindAny:
+		// Ошибки и структуры проверяются по их тексту; без совпадений
+		// значение остаётся как есть
+		text := fmt.Sprintf("%+v", value.Any())
+		if clean := r.String(text); clean != text {
+			return slog.String(a.Key, clean)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: value}
+}
+
+// Sensitive проверяет, скрывается ли значение поля с этим именем целиком
+func (r *Redactor) Sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range r.keys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// String маскирует совпадения шаблонов в тексте
+func (r *Redactor) String(s string) string {
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllStringFunc(s, p.mask)
+	}
+	return s
+}
+
+// JSON маскирует значение, разобранное encoding/json: поля объектов по
+// имени, строки по шаблонам
+func (r *Redactor) JSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if r.Sensitive(key) {
+				v[key] = redacted
+			} else {
+				v[key] = r.JSON(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = r.JSON(value)
+		}
+	case string:
+		return r.String(v)
+	}
+	return v
+}
+
+// maskDigits оставляет две последние цифры
+func maskDigits(match string) string {
+	digits := 0
+	for _, r := range match {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range match {
+		if r >= '0' && r <= '9' {
+			if digits > 2 {
+				r = '*'
+			}
+			digits--
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// maskEmail оставляет первую букву имени и домен
+func maskEmail(match string) string {
+	name, domain, _ := strings.Cut(match, "@")
+	if len(name) > 1 {
+		name = name[:1] + "***"
+	}
+	return name + "@" + domain
+}
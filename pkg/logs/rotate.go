@@ -0,0 +1,247 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat время ротации в имени сегмента, сортируется как строка
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotateOptions настройки ротации
+type RotateOptions struct {
+	// MaxSize размер в байтах, после которого файл ротируется
+	MaxSize int64
+	// MaxAge возраст сегмента, после которого он ротируется; 0 отключает.
+	// Возраст считается с момента, когда шлюз открыл сегмент
+	MaxAge time.Duration
+	// Backups сколько старых сегментов хранить
+	Backups int
+	// Compress сжимать старые сегменты gzip
+	Compress bool
+}
+
+// RotatingFile файл лога с ротацией по размеру и возрасту. Старый сегмент
+// переименовывается в name-<время>.ext, сжимается и удаляется сверх
+// Backups в фоне. Write безопасен для одновременных вызовов
+type RotatingFile struct {
+	path string
+	opts RotateOptions
+
+	mu     sync.Mutex
+	file   *os.File // nil после неудачной ротации, открывается заново
+	size   int64
+	opened time.Time
+	closed bool
+
+	mill chan struct{}
+	done chan struct{}
+}
+
+// Создание файла с ротацией. Каталог создаётся при необходимости
+func NewRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	f := &RotatingFile{
+		path: path,
+		opts: opts,
+		mill: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	go f.millLoop()
+	// Сегменты, оставшиеся от прошлого запуска, тоже подчищаются
+	f.mill <- struct{}{}
+
+	return f, nil
+}
+
+// open открывает текущий файл. Файл, не менявшийся дольше MaxAge, сразу
+// уходит в архив
+func (f *RotatingFile) open() error {
+	info, err := os.Stat(f.path)
+	if err == nil && info.Size() > 0 && f.opts.MaxAge > 0 && time.Since(info.ModTime()) >= f.opts.MaxAge {
+		if err := os.Rename(f.path, f.backupName(time.Now())); err != nil {
+			return err
+		}
+		info = nil
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	f.file = file
+	f.size = 0
+	if info != nil {
+		f.size = info.Size()
+	}
+	f.opened = time.Now()
+	return nil
+}
+
+// Write дописывает p, предварительно ротируя файл, если он вырос бы
+// больше MaxSize или старше MaxAge
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	tooBig := f.size > 0 && f.size+int64(len(p)) > f.opts.MaxSize
+	tooOld := f.opts.MaxAge > 0 && time.Since(f.opened) >= f.opts.MaxAge
+	if tooBig || tooOld {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate начинает новый сегмент
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+func (f *RotatingFile) rotate() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		f.file = nil
+	}
+
+	if err := os.Rename(f.path, f.backupName(time.Now())); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	select {
+	case f.mill <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (f *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	return strings.TrimSuffix(f.path, ext) + "-" + t.UTC().Format(backupTimeFormat) + ext
+}
+
+// millLoop сжимает и удаляет старые сегменты, пока файл не закрыт
+func (f *RotatingFile) millLoop() {
+	defer close(f.done)
+	for range f.mill {
+		f.millOnce()
+	}
+}
+
+func (f *RotatingFile) millOnce() {
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(filepath.Base(f.path), ext) + "-"
+
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return
+	}
+
+	var backups []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz"), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, filepath.Join(filepath.Dir(f.path), name))
+		}
+	}
+
+	// Новые сегменты первыми
+	slices.SortFunc(backups, func(a, b string) int { return strings.Compare(b, a) })
+
+	for i, backup := range backups {
+		switch {
+		case i >= f.opts.Backups:
+			os.Remove(backup)
+		case f.opts.Compress && !strings.HasSuffix(backup, ".gz"):
+			compress(backup)
+		}
+	}
+}
+
+// compress заменяет файл его gzip-копией
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	err = errors.Join(err, zw.Close(), dst.Close())
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// Close закрывает файл и дожидается фоновой обработки сегментов
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return os.ErrClosed
+	}
+	f.closed = true
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	close(f.mill)
+	f.mu.Unlock()
+
+	<-f.done
+	return err
+}
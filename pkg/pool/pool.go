@@ -0,0 +1,310 @@
+// Package pool spreads the calls to one backend service over several
+// endpoints, with round-robin or least-request balancing, ejection of
+// failing endpoints and endpoint lists that are refreshed from DNS or a
+// local file.
+package pool
+
+import (
+	"context"
+	"errors"
+	"gateway/config"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// closeDelay is how long a removed endpoint keeps serving the calls that
+// were already sent to it.
+const closeDelay = 30 * time.Second
+
+type endpoint struct {
+	Address
+	conn     *grpc.ClientConn
+	inflight atomic.Int64
+
+	mu           sync.Mutex
+	failures     int
+	ejectedUntil time.Time
+}
+
+func (e *endpoint) ejected(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return now.Before(e.ejectedUntil)
+}
+
+// Pool implements grpc.ClientConnInterface over the endpoints of one
+// backend. Interceptors run once per call, before an endpoint is picked,
+// so a retry may go to another endpoint.
+type Pool struct {
+	name     string
+	opts     config.Backend
+	dialOpts []grpc.DialOption
+	invoke   grpc.UnaryInvoker
+	log      *slog.Logger
+
+	mu        sync.RWMutex
+	endpoints []*endpoint
+	next      atomic.Uint64
+}
+
+// New creates the pool of backend name and resolves its endpoints. A failed
+// resolution is logged and retried by Watch; until then calls fail with
+// Unavailable.
+func New(ctx context.Context, name string, opts config.Backend, dialOpts []grpc.DialOption,
+	interceptors []grpc.UnaryClientInterceptor, log *slog.Logger) *Pool {
+	p := &Pool{name: name, opts: opts, dialOpts: dialOpts, log: log}
+	p.invoke = chain(interceptors, p.call)
+
+	if err := p.Refresh(ctx); err != nil {
+		log.Error("Error resolving backend endpoints", "backend", name, "error", err.Error())
+	}
+
+	return p
+}
+
+// Invoke performs a unary RPC on one of the endpoints.
+func (p *Pool) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	return p.invoke(ctx, method, args, reply, nil, opts...)
+}
+
+// NewStream opens a stream on one of the endpoints. Interceptors and
+// outlier detection only apply to unary calls.
+func (p *Pool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	e := p.pick()
+	if e == nil {
+		return nil, p.noEndpoints()
+	}
+	return e.conn.NewStream(ctx, desc, method, opts...)
+}
+
+// call is the last invoker of the chain: it picks an endpoint and sends the
+// call there.
+func (p *Pool) call(ctx context.Context, method string, req, reply any, _ *grpc.ClientConn, opts ...grpc.CallOption) error {
+	e := p.pick()
+	if e == nil {
+		return p.noEndpoints()
+	}
+
+	e.inflight.Add(1)
+	err := e.conn.Invoke(ctx, method, req, reply, opts...)
+	e.inflight.Add(-1)
+
+	p.record(e, err)
+	return err
+}
+
+func (p *Pool) noEndpoints() error {
+	return status.Errorf(codes.Unavailable, "%s service has no endpoints", p.name)
+}
+
+// pick chooses among the endpoints that are not ejected, or among all of
+// them when every endpoint is ejected.
+func (p *Pool) pick() *endpoint {
+	p.mu.RLock()
+	endpoints := p.endpoints
+	p.mu.RUnlock()
+
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	healthy := make([]*endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		if !e.ejected(now) {
+			healthy = append(healthy, e)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = endpoints
+	}
+
+	start := int(p.next.Add(1) % uint64(len(healthy)))
+	if p.opts.Balancer != "least_request" {
+		return healthy[start]
+	}
+
+	// Scanning from the round-robin position spreads ties.
+	best := healthy[start]
+	for i := 1; i < len(healthy); i++ {
+		e := healthy[(start+i)%len(healthy)]
+		if e.inflight.Load() < best.inflight.Load() {
+			best = e
+		}
+	}
+	return best
+}
+
+// record counts consecutive transport failures of an endpoint and ejects
+// it for OutlierEjection after OutlierFailures of them, as long as no more
+// than MaxEjectedPercent of the endpoints are ejected.
+func (p *Pool) record(e *endpoint, err error) {
+	if p.opts.OutlierFailures == 0 {
+		return
+	}
+
+	code := status.Code(err)
+	failed := code == codes.Unavailable || code == codes.DeadlineExceeded
+
+	e.mu.Lock()
+	if !failed {
+		e.failures = 0
+		e.mu.Unlock()
+		return
+	}
+	e.failures++
+	eject := e.failures >= p.opts.OutlierFailures
+	e.mu.Unlock()
+
+	if !eject {
+		return
+	}
+
+	p.mu.RLock()
+	endpoints := p.endpoints
+	p.mu.RUnlock()
+
+	now := time.Now()
+	ejected := 0
+	for _, other := range endpoints {
+		if other.ejected(now) {
+			ejected++
+		}
+	}
+	if (ejected+1)*100 > p.opts.MaxEjectedPercent*len(endpoints) {
+		return
+	}
+
+	e.mu.Lock()
+	e.failures = 0
+	e.ejectedUntil = now.Add(p.opts.OutlierEjection)
+	e.mu.Unlock()
+
+	p.log.Warn("Ejected backend endpoint", "backend", p.name, "endpoint", e.Addr, "for", p.opts.OutlierEjection.String())
+}
+
+// Update replaces the endpoints with addrs. Endpoints that stay keep their
+// connection and state; removed ones are closed after closeDelay.
+func (p *Pool) Update(addrs []Address) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current := make(map[Address]*endpoint, len(p.endpoints))
+	for _, e := range p.endpoints {
+		current[e.Address] = e
+	}
+
+	endpoints := make([]*endpoint, 0, len(addrs))
+	for _, addr := range addrs {
+		if e, ok := current[addr]; ok {
+			endpoints = append(endpoints, e)
+			delete(current, addr)
+			continue
+		}
+
+		opts := p.dialOpts
+		if addr.Authority != "" {
+			opts = append(opts[:len(opts):len(opts)], grpc.WithAuthority(addr.Authority))
+		}
+		conn, err := grpc.NewClient(addr.Addr, opts...)
+		if err != nil {
+			for _, e := range endpoints {
+				if !slices.Contains(p.endpoints, e) {
+					e.conn.Close()
+				}
+			}
+			return err
+		}
+		endpoints = append(endpoints, &endpoint{Address: addr, conn: conn})
+	}
+
+	if len(current) == 0 && len(endpoints) == len(p.endpoints) {
+		return nil
+	}
+
+	for _, e := range current {
+		time.AfterFunc(closeDelay, func() { e.conn.Close() })
+	}
+
+	p.endpoints = endpoints
+	p.log.Info("Updated backend endpoints", "backend", p.name, "endpoints", p.addrs())
+
+	return nil
+}
+
+// Refresh resolves the endpoints again and applies them.
+func (p *Pool) Refresh(ctx context.Context) error {
+	addrs, err := Resolve(ctx, p.opts.Addresses, p.opts.EndpointsFile)
+	if err != nil {
+		return err
+	}
+	return p.Update(addrs)
+}
+
+// Watch refreshes the endpoints every ResolveInterval until ctx is done.
+// Failed refreshes keep the current endpoints.
+func (p *Pool) Watch(ctx context.Context) {
+	ticker := time.NewTicker(p.opts.ResolveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := p.Refresh(ctx); err != nil && ctx.Err() == nil {
+			p.log.Error("Error refreshing backend endpoints, keeping the current ones", "backend", p.name, "error", err.Error())
+		}
+	}
+}
+
+// Target lists the current endpoint addresses.
+func (p *Pool) Target() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return strings.Join(p.addrs(), ",")
+}
+
+func (p *Pool) addrs() []string {
+	addrs := make([]string, len(p.endpoints))
+	for i, e := range p.endpoints {
+		addrs[i] = e.Addr
+	}
+	return addrs
+}
+
+// Close closes the connections of every endpoint.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs []error
+	for _, e := range p.endpoints {
+		errs = append(errs, e.conn.Close())
+	}
+	p.endpoints = nil
+
+	return errors.Join(errs...)
+}
+
+// chain wraps final in interceptors, the first one outermost.
+func chain(interceptors []grpc.UnaryClientInterceptor, final grpc.UnaryInvoker) grpc.UnaryInvoker {
+	invoke := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoke
+		invoke = func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return interceptor(ctx, method, req, reply, cc, next, opts...)
+		}
+	}
+	return invoke
+}
@@ -0,0 +1,97 @@
+package pool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// dnsScheme marks targets that are resolved to all their addresses.
+const dnsScheme = "dns:///"
+
+// Address is one endpoint. Authority is the host name of a DNS target,
+// used as the :authority and TLS server name instead of the IP.
+type Address struct {
+	Addr      string
+	Authority string
+}
+
+// Resolve expands targets into endpoints. When file is set its lines, minus
+// blank lines and # comments, replace targets. A target "dns:///host:port"
+// becomes one endpoint per address of host; any other target is used as it
+// is.
+func Resolve(ctx context.Context, targets []string, file string) ([]Address, error) {
+	if file != "" {
+		var err error
+		if targets, err = readTargets(file); err != nil {
+			return nil, err
+		}
+	}
+
+	var addrs []Address
+	seen := make(map[Address]bool)
+	for _, target := range targets {
+		resolved, err := resolveTarget(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range resolved {
+			if !seen[addr] {
+				seen[addr] = true
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no endpoints in %q", strings.Join(targets, ","))
+	}
+
+	return addrs, nil
+}
+
+func resolveTarget(ctx context.Context, target string) ([]Address, error) {
+	hostPort, ok := strings.CutPrefix(target, dnsScheme)
+	if !ok {
+		return []Address{{Addr: target}}, nil
+	}
+
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", target, err)
+	}
+
+	ips, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]Address, len(ips))
+	for i, ip := range ips {
+		addrs[i] = Address{Addr: net.JoinHostPort(ip, port), Authority: hostPort}
+	}
+	return addrs, nil
+}
+
+func readTargets(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		targets = append(targets, line)
+	}
+
+	return targets, scanner.Err()
+}
@@ -0,0 +1,204 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"gateway/config"
+	"gateway/pkg/resilience"
+	"log/slog"
+	"math/rand/v2"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// maxShadowCalls bounds the mirrored calls in flight; mirrors beyond it
+// are dropped.
+const maxShadowCalls = 64
+
+// Route sends the calls of one backend to its primary pool or, by weight
+// per method or by company, to a canary pool, and mirrors read calls to a
+// shadow pool. Interceptors run once per call, before the pool is chosen.
+type Route struct {
+	name    string
+	opts    config.Backend
+	primary *Pool
+	canary  *Pool
+	shadow  *Pool
+	invoke  grpc.UnaryInvoker
+	log     *slog.Logger
+
+	shadowSlots chan struct{}
+}
+
+// NewRoute combines the pools of backend name. canary and shadow may be nil.
+func NewRoute(name string, opts config.Backend, primary, canary, shadow *Pool,
+	interceptors []grpc.UnaryClientInterceptor, log *slog.Logger) *Route {
+	r := &Route{
+		name:        name,
+		opts:        opts,
+		primary:     primary,
+		canary:      canary,
+		shadow:      shadow,
+		log:         log,
+		shadowSlots: make(chan struct{}, maxShadowCalls),
+	}
+	r.invoke = chain(interceptors, r.call)
+
+	return r
+}
+
+// Invoke performs a unary RPC on the primary or the canary pool.
+func (r *Route) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	return r.invoke(ctx, method, args, reply, nil, opts...)
+}
+
+// NewStream opens a stream on the primary pool.
+func (r *Route) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return r.primary.NewStream(ctx, desc, method, opts...)
+}
+
+func (r *Route) call(ctx context.Context, method string, req, reply any, _ *grpc.ClientConn, opts ...grpc.CallOption) error {
+	target := r.primary
+	if r.toCanary(ctx, method) {
+		target = r.canary
+	}
+
+	err := target.Invoke(ctx, method, req, reply, opts...)
+
+	if err == nil && r.mirrored(method) {
+		r.mirror(ctx, method, req, reply)
+	}
+
+	return err
+}
+
+// toCanary pins the companies of CanaryCompanies to the canary and sends it
+// the CanaryWeights share of the other calls of method, falling back to the
+// weight of "*".
+func (r *Route) toCanary(ctx context.Context, method string) bool {
+	if r.canary == nil {
+		return false
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	for _, company := range md.Get("company_id") {
+		if slices.Contains(r.opts.CanaryCompanies, company) {
+			return true
+		}
+	}
+
+	weight, ok := r.opts.CanaryWeights[path.Base(method)]
+	if !ok {
+		weight = r.opts.CanaryWeights["*"]
+	}
+
+	return weight > 0 && rand.IntN(100) < weight
+}
+
+// mirrored reports whether a successful call of method is copied to the
+// shadow pool: it must be a read (RetryMethods) and fall in ShadowPercent.
+func (r *Route) mirrored(method string) bool {
+	return r.shadow != nil && resilience.Retryable(method, r.opts.RetryMethods) && rand.IntN(100) < r.opts.ShadowPercent
+}
+
+// mirror sends a copy of the call to the shadow pool in the background and
+// logs how its response differs. The caller's response is never touched.
+func (r *Route) mirror(ctx context.Context, method string, req, reply any) {
+	reqMsg, ok1 := req.(proto.Message)
+	replyMsg, ok2 := reply.(proto.Message)
+	if !ok1 || !ok2 {
+		return
+	}
+
+	select {
+	case r.shadowSlots <- struct{}{}:
+	default:
+		r.log.Warn("Dropped shadow call, too many in flight", "backend", r.name, "method", method)
+		return
+	}
+
+	// The request context ends with the request and its values may be
+	// reused, so the shadow call only keeps a copy of the outgoing metadata.
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set("x-shadow", "true")
+
+	reqMsg = proto.Clone(reqMsg)
+	want := proto.Clone(replyMsg)
+
+	go func() {
+		defer func() { <-r.shadowSlots }()
+
+		ctx, cancel := context.WithTimeout(metadata.NewOutgoingContext(context.Background(), md), r.opts.Timeout)
+		defer cancel()
+
+		got := want.ProtoReflect().New().Interface()
+		if err := r.shadow.Invoke(ctx, method, reqMsg, got); err != nil {
+			r.log.Warn("Shadow call failed", "backend", r.name, "method", method, "error", err.Error())
+			return
+		}
+
+		if fields := diff(want.ProtoReflect(), got.ProtoReflect()); len(fields) > 0 {
+			r.log.Warn("Shadow response differs", "backend", r.name, "method", method,
+				"request_id", strings.Join(md.Get("request_id"), ","), "fields", fields)
+		}
+	}()
+}
+
+// diff returns the names of the top-level fields that differ.
+func diff(a, b protoreflect.Message) []string {
+	var fields []string
+	descs := a.Descriptor().Fields()
+	for i := 0; i < descs.Len(); i++ {
+		fd := descs.Get(i)
+		if a.Has(fd) != b.Has(fd) || !a.Get(fd).Equal(b.Get(fd)) {
+			fields = append(fields, string(fd.Name()))
+		}
+	}
+	return fields
+}
+
+// Watch keeps the endpoints of every pool of the route up to date until
+// ctx is done.
+func (r *Route) Watch(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, p := range r.pools() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Watch(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// Target lists the endpoints of the primary pool.
+func (r *Route) Target() string {
+	return r.primary.Target()
+}
+
+// Close closes every pool of the route.
+func (r *Route) Close() error {
+	var errs []error
+	for _, p := range r.pools() {
+		errs = append(errs, p.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Route) pools() []*Pool {
+	pools := []*Pool{r.primary}
+	for _, p := range []*Pool{r.canary, r.shadow} {
+		if p != nil {
+			pools = append(pools, p)
+		}
+	}
+	return pools
+}
@@ -0,0 +1,131 @@
+package resilience
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	closed breakerState = iota
+	open
+	halfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case open:
+		return "open"
+	case halfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker is a consecutive-failure circuit breaker. After failures
+// failures in a row it opens and rejects calls for cooldown, then lets a
+// single trial call through: its success closes the breaker, its failure
+// opens it again.
+type Breaker struct {
+	name     string
+	failures int
+	cooldown time.Duration
+	log      *slog.Logger
+
+	mu       sync.Mutex
+	state    breakerState
+	count    int
+	openedAt time.Time
+	trial    bool
+}
+
+// NewBreaker returns a breaker for the backend name. A failures of zero
+// returns nil, which allows every call.
+func NewBreaker(name string, failures int, cooldown time.Duration, log *slog.Logger) *Breaker {
+	if failures <= 0 {
+		return nil
+	}
+	return &Breaker{name: name, failures: failures, cooldown: cooldown, log: log}
+}
+
+// Allow reports whether a call may go ahead. Every allowed call must be
+// followed by Done.
+func (b *Breaker) Allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case open:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.setState(halfOpen)
+		fallthrough
+	case halfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+	}
+
+	return true
+}
+
+// Done records the outcome of an allowed call.
+func (b *Breaker) Done(failed bool) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == halfOpen {
+		b.trial = false
+		if failed {
+			b.openedAt = time.Now()
+			b.setState(open)
+		} else {
+			b.count = 0
+			b.setState(closed)
+		}
+		return
+	}
+
+	if !failed {
+		b.count = 0
+		return
+	}
+
+	b.count++
+	if b.state == closed && b.count >= b.failures {
+		b.openedAt = time.Now()
+		b.setState(open)
+	}
+}
+
+// State returns "closed", "open" or "half-open".
+func (b *Breaker) State() string {
+	if b == nil {
+		return closed.String()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state.String()
+}
+
+func (b *Breaker) setState(state breakerState) {
+	if b.state == state {
+		return
+	}
+	b.log.Warn("Circuit breaker changed state", "backend", b.name, "from", b.state.String(), "to", state.String())
+	b.state = state
+}
@@ -0,0 +1,113 @@
+// Package resilience provides the gRPC client interceptors of the gateway:
+// default deadlines, retries of idempotent reads and circuit breaking.
+package resilience
+
+import (
+	"context"
+	"gateway/config"
+	"log/slog"
+	"math/rand/v2"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor applies the settings of one backend to its RPCs.
+// The deadline covers all attempts; every attempt goes through the breaker.
+func UnaryClientInterceptor(name string, opts config.Backend, log *slog.Logger) grpc.UnaryClientInterceptor {
+	breaker := NewBreaker(name, opts.BreakerFailures, opts.BreakerCooldown, log)
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+			defer cancel()
+		}
+
+		attempts := 1
+		if Retryable(method, opts.RetryMethods) {
+			attempts += opts.Retries
+		}
+
+		var err error
+		for attempt := 0; attempt < attempts; attempt++ {
+			if attempt > 0 {
+				if !sleep(ctx, backoff(attempt, opts.RetryBackoff, opts.RetryBackoffMax)) {
+					break
+				}
+				log.Warn("Retrying gRPC call", "backend", name, "method", method, "attempt", attempt+1, "error", err.Error())
+			}
+
+			if !breaker.Allow() {
+				return status.Errorf(codes.Unavailable, "%s service is unavailable, circuit breaker is open", name)
+			}
+
+			err = invoker(ctx, method, req, reply, cc, callOpts...)
+			breaker.Done(failure(err))
+
+			if err == nil || !transient(err) {
+				return err
+			}
+		}
+
+		return err
+	}
+}
+
+// Retryable reports whether the method name starts with one of prefixes,
+// i.e. is an idempotent read.
+func Retryable(method string, prefixes []string) bool {
+	name := path.Base(method)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// failure reports whether err means the backend is unhealthy, as opposed
+// to rejecting the request.
+func failure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// transient reports whether a retry may succeed.
+func transient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// backoff returns a random wait in [0, min(max, base*2^(attempt-1))), the
+// "full jitter" strategy, so retries of many callers spread out.
+func backoff(attempt int, base, max time.Duration) time.Duration {
+	d := base << (attempt - 1)
+	if d > max || d <= 0 {
+		d = max
+	}
+	return rand.N(d)
+}
+
+// sleep waits for d and reports false when ctx ends first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}